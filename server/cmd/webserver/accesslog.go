@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+// what accessLogger.Log does when the queue is full
+const (
+	accessLogPolicyDrop  = "drop"  // discard the entry and count it, never slowing down requests
+	accessLogPolicyBlock = "block" // wait for room in the queue, slowing down requests instead of losing entries
+)
+
+// accessLogEntry is a snapshot of a completed request. Everything is copied out of the request
+// in the handler so the writer goroutine never touches it after the handler has returned.
+type accessLogEntry struct {
+	logger    *zap.Logger // carries the request-scoped fields, see log.FromContext
+	time      time.Time
+	ip        string
+	method    string
+	uri       string
+	proto     string
+	referer   string
+	userAgent string
+	status    int
+	bytes     int64
+	duration  time.Duration
+	err       error
+}
+
+// accessLogger writes access log entries from a single goroutine fed by a bounded queue, so a
+// burst of requests can't create an unbounded number of goroutines. Entries are always written to
+// the structured log; if combined is set they're also written there in NCSA combined log format.
+type accessLogger struct {
+	entries  chan accessLogEntry
+	policy   string
+	combined io.Writer
+	dropped  uint64 // accessed atomically
+	done     chan struct{}
+	close    sync.Once
+
+	// mu guards closed; Log holds it for reading while it sends, so Close can't close entries
+	// under it
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newAccessLogger(queueSize int, policy string, combined io.Writer) *accessLogger {
+	a := &accessLogger{
+		entries:  make(chan accessLogEntry, queueSize),
+		policy:   policy,
+		combined: combined,
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Log queues an entry. When the queue is full the entry is dropped or the caller blocks, depending
+// on the policy. Entries logged after Close, by handlers still running when the server's shutdown
+// timed out, are dropped.
+func (a *accessLogger) Log(entry accessLogEntry) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		atomic.AddUint64(&a.dropped, 1)
+		return
+	}
+	if a.policy == accessLogPolicyBlock {
+		a.entries <- entry
+		return
+	}
+
+	select {
+	case a.entries <- entry:
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+}
+
+// Dropped returns the number of entries discarded because the queue was full.
+func (a *accessLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Close writes the entries still queued and waits for them to be flushed. Later entries are dropped.
+func (a *accessLogger) Close() {
+	a.close.Do(func() {
+		a.mu.Lock()
+		a.closed = true
+		close(a.entries)
+		a.mu.Unlock()
+		<-a.done
+
+		if dropped := a.Dropped(); dropped > 0 {
+			log.Warn("access log entries were dropped", zap.Uint64("dropped", dropped))
+		}
+	})
+}
+
+func (a *accessLogger) run() {
+	defer close(a.done)
+
+	for entry := range a.entries {
+		writeHTTPLog(entry)
+
+		if a.combined != nil {
+			if _, err := io.WriteString(a.combined, entry.combinedFormat()); err != nil {
+				log.Error("writing combined access log failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// newAccessLogEntry captures the details of a completed request.
+func newAccessLogEntry(r *http.Request, ip string, start time.Time, status int, bytes int64, err error) accessLogEntry {
+	return accessLogEntry{
+		logger:    log.FromContext(r.Context()),
+		time:      start,
+		ip:        ip,
+		method:    r.Method,
+		uri:       r.RequestURI,
+		proto:     r.Proto,
+		referer:   r.Referer(),
+		userAgent: r.UserAgent(),
+		status:    status,
+		bytes:     bytes,
+		duration:  time.Since(start),
+		err:       err,
+	}
+}
+
+// writeHTTPLog writes the following keys to the log entry:
+//
+//	http_status          The HTTP status code returned.
+//	ip                   The client IP address, see clientip.Resolver.
+//	method               GET, POST, PUT, DELETE, etc
+//	time_taken           The time taken to complete the request in milliseconds.
+//	uri                  The request URI.
+//
+// along with the request-scoped fields carried by the request context, such as request_id and trace_id.
+//
+// The log level is determined by the status code:
+//
+//	status < 400          Info
+//	400 <= status < 500   Warning
+//	status >= 500         Error
+func writeHTTPLog(entry accessLogEntry) {
+	timeTakenSecs := float64(entry.duration) / 1e9
+
+	fields := []zap.Field{
+		zap.Int("http_status", entry.status),
+		zap.String("ip", entry.ip),
+		zap.String("method", entry.method),
+		zap.Int64("time_taken", int64(timeTakenSecs*1000)),
+		zap.String("uri", entry.uri),
+	}
+
+	msg := http.StatusText(entry.status)
+	if entry.err != nil {
+		fields = append(fields, zap.Error(entry.err))
+	}
+
+	if entry.status >= 400 && entry.status < 500 {
+		entry.logger.Warn(msg, fields...)
+	} else if entry.status >= 500 {
+		entry.logger.Error(msg, fields...)
+	} else {
+		entry.logger.Info(msg, fields...)
+	}
+}
+
+// combinedFormat formats the entry in the NCSA combined log format used by Apache and nginx:
+//
+//	host ident authuser [date] "request" status bytes "referer" "user-agent"
+func (e accessLogEntry) combinedFormat() string {
+	bytes := "-"
+	if e.bytes > 0 {
+		bytes = strconv.FormatInt(e.bytes, 10)
+	}
+
+	return fmt.Sprintf("%s - - [%s] %s %d %s %s %s\n",
+		e.ip,
+		e.time.Format("02/Jan/2006:15:04:05 -0700"),
+		strconv.Quote(e.method+" "+e.uri+" "+e.proto),
+		e.status,
+		bytes,
+		quoteOrDash(e.referer),
+		quoteOrDash(e.userAgent),
+	)
+}
+
+// quoteOrDash quotes s, or returns "-" quoted if it's empty as Apache does.
+func quoteOrDash(s string) string {
+	if s == "" {
+		s = "-"
+	}
+	return strconv.Quote(s)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestAccessLogger_DropsWhenQueueIsFull(t *testing.T) {
+	// arrange
+	// build the logger by hand so nothing drains the queue
+	a := &accessLogger{
+		entries: make(chan accessLogEntry, 2),
+		policy:  accessLogPolicyDrop,
+		done:    make(chan struct{}),
+	}
+
+	// act
+	for i := 0; i < 5; i++ {
+		a.Log(accessLogEntry{})
+	}
+
+	// assert
+	if got := a.Dropped(); got != 3 {
+		t.Errorf("Dropped, want: 3 got: %d", got)
+	}
+}
+
+func TestAccessLogger_CloseFlushesQueuedEntries(t *testing.T) {
+	// arrange
+	var combined bytes.Buffer
+	a := newAccessLogger(10, accessLogPolicyBlock, &combined)
+
+	// act
+	for i := 0; i < 3; i++ {
+		a.Log(accessLogEntry{logger: zap.NewNop(), method: "GET", uri: "/ping", proto: "HTTP/1.1", status: 200})
+	}
+	a.Close()
+
+	// assert
+	if got := bytes.Count(combined.Bytes(), []byte("\n")); got != 3 {
+		t.Errorf("lines written, want: 3 got: %d", got)
+	}
+}
+
+func TestAccessLogger_DropsEntriesLoggedAfterClose(t *testing.T) {
+	// arrange
+	var combined bytes.Buffer
+	a := newAccessLogger(10, accessLogPolicyBlock, &combined)
+	a.Close()
+
+	// act
+	a.Log(accessLogEntry{logger: zap.NewNop(), method: "GET", uri: "/ping", proto: "HTTP/1.1", status: 200})
+
+	// assert
+	if got := a.Dropped(); got != 1 {
+		t.Errorf("Dropped, want: 1 got: %d", got)
+	}
+	if combined.Len() != 0 {
+		t.Errorf("combined log, want: empty got: %q", combined.String())
+	}
+}
+
+func TestAccessLogEntry_CombinedFormat(t *testing.T) {
+	// arrange
+	entry := accessLogEntry{
+		time:      time.Date(2019, 4, 7, 18, 38, 24, 0, time.FixedZone("CDT", -5*60*60)),
+		ip:        "10.0.0.1",
+		method:    "GET",
+		uri:       "/api/v1/contacts?x=1",
+		proto:     "HTTP/1.1",
+		userAgent: "curl/7.54.0",
+		status:    200,
+		bytes:     512,
+	}
+
+	// act
+	got := entry.combinedFormat()
+
+	// assert
+	want := `10.0.0.1 - - [07/Apr/2019:18:38:24 -0500] "GET /api/v1/contacts?x=1 HTTP/1.1" 200 512 "-" "curl/7.54.0"` + "\n"
+	if got != want {
+		t.Errorf("combinedFormat\nwant: %s got:  %s", want, got)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// routeMethods records the methods registered for each route path, so a 405 can say which
+// methods the path does support.
+type routeMethods []struct {
+	path    *regexp.Regexp
+	methods []string
+}
+
+// collectRouteMethods walks the router once its routes are registered. Routes without a method
+// matcher, such as /swagger/, accept every method and are skipped.
+func collectRouteMethods(router *mux.Router) (routeMethods, error) {
+	var routes routeMethods
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		pattern, err := route.GetPathRegexp()
+		if err != nil {
+			return nil
+		}
+		path, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, struct {
+			path    *regexp.Regexp
+			methods []string
+		}{path, methods})
+		return nil
+	})
+	return routes, err
+}
+
+// allowed returns the methods registered for path, plus OPTIONS which is always answered.
+func (routes routeMethods) allowed(path string) []string {
+	set := map[string]bool{http.MethodOptions: true}
+	for _, route := range routes {
+		if route.path.MatchString(path) {
+			for _, method := range route.methods {
+				set[method] = true
+			}
+		}
+	}
+
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// methodNotAllowedHandler handles requests whose path matched a route but whose method didn't.
+// OPTIONS is answered with the allowed methods; anything else is a 405 listing them in Allow.
+func methodNotAllowedHandler(routes routeMethods) func(http.ResponseWriter, *http.Request) error {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Allow", strings.Join(routes.allowed(r.URL.Path), ", "))
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		}
+		return &methodNotAllowed{}
+	}
+}
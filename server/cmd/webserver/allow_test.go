@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+)
+
+func newAllowTestRouter(t *testing.T) *mux.Router {
+	t.Helper()
+	return newTestWebserver().router()
+}
+
+func TestMethodNotAllowed_ReturnsJSONWithAllow(t *testing.T) {
+	// arrange
+	router := newAllowTestRouter(t)
+	req := httptest.NewRequest("PATCH", "/api/v1/contacts/1", nil)
+	rec := httptest.NewRecorder()
+
+	// act
+	router.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status, want: %d got: %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+	if got, want := rec.Header().Get("Allow"), "DELETE, GET, OPTIONS, PUT"; got != want {
+		t.Errorf("Allow, want: %q got: %q", want, got)
+	}
+
+	var body models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "method not allowed" {
+		t.Errorf("Error, want: %q got: %q", "method not allowed", body.Error)
+	}
+}
+
+func TestMethodNotAllowed_AnswersOptions(t *testing.T) {
+	// arrange
+	router := newAllowTestRouter(t)
+	req := httptest.NewRequest("OPTIONS", "/api/v1/contacts", nil)
+	rec := httptest.NewRecorder()
+
+	// act
+	router.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status, want: %d got: %d", http.StatusNoContent, rec.Code)
+	}
+	if got, want := rec.Header().Get("Allow"), "GET, OPTIONS, POST"; got != want {
+		t.Errorf("Allow, want: %q got: %q", want, got)
+	}
+}
+
+func TestMethodNotAllowed_NamedPathsOnlyListTheirOwnMethods(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/v1/contacts/export", want: "GET, OPTIONS"},
+		{path: "/api/v1/contacts/import", want: "OPTIONS, POST"},
+		{path: "/api/v1/contacts/near", want: "GET, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		router := newAllowTestRouter(t)
+		req := httptest.NewRequest("PUT", tt.path, nil)
+		rec := httptest.NewRecorder()
+
+		// act
+		router.ServeHTTP(rec, req)
+
+		// assert
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: status, want: %d got: %d", tt.path, http.StatusMethodNotAllowed, rec.Code)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.want {
+			t.Errorf("%s: Allow, want: %q got: %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestMethodNotAllowed_UnknownPathIsStillNotFound(t *testing.T) {
+	// arrange
+	router := newAllowTestRouter(t)
+	req := httptest.NewRequest("PATCH", "/api/v1/nothing", nil)
+	rec := httptest.NewRecorder()
+
+	// act
+	router.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status, want: %d got: %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+const (
+	// defaultAuditLimit and maxAuditLimit bound the entries an audit log request returns.
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// @Summary Get a contact's history
+// @Description Returns the audit entries of the contact and its addresses, newest first, including those of a
+// @Description deleted contact. Pass the id of the last entry as before to get the page after it.
+// @Param contactID path int true "Contact ID"
+// @Param before query int false "Only entries older than this entry ID"
+// @Param limit query int false "The most entries to return, up to 1000" default(100)
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.AuditEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /contacts/{contactID}/history [get]
+func (ws *webserver) handleGetContactHistory(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get query params
+	query := r.URL.Query()
+	filter := database.AuditFilter{ContactID: id}
+	if filter.BeforeID, err = queryID(query, "before"); err != nil {
+		return err
+	}
+	limit, err := auditLimit(query)
+	if err != nil {
+		return err
+	}
+
+	entries, err := db.Audit.Find(filter, limit)
+	if err != nil {
+		return err
+	}
+	// a contact that never existed has no history; one that was deleted still has its own
+	if len(entries) == 0 && filter.BeforeID == 0 {
+		if _, err := db.Contacts.Get(id); err != nil {
+			return err
+		}
+	}
+
+	return Ok(w, models.MapAuditEntryResponses(entries))
+}
+
+// @Summary Query the audit log
+// @Description Returns the audit entries matching every given filter, newest first. Every create, update and
+// @Description delete of a contact or address is recorded, as are merges. Pass the id of the last entry as
+// @Description before to get the page after it.
+// @Param entity query string false "contact or address" Enums(contact, address)
+// @Param entityId query int false "Entity ID"
+// @Param contactId query int false "Entries about this contact or its addresses"
+// @Param action query string false "create, update, delete or merge" Enums(create, update, delete, merge)
+// @Param actor query string false "The user who made the change"
+// @Param requestId query string false "The request that made the change"
+// @Param since query int false "Only entries recorded at or after this time, in milliseconds since the epoch"
+// @Param until query int false "Only entries recorded before this time, in milliseconds since the epoch"
+// @Param before query int false "Only entries older than this entry ID"
+// @Param limit query int false "The most entries to return, up to 1000" default(100)
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.AuditEntryResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /audit [get]
+func (ws *webserver) handleGetAuditEntries(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get query params
+	filter, limit, err := auditQuery(r)
+	if err != nil {
+		return err
+	}
+
+	entries, err := db.Audit.Find(filter, limit)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, models.MapAuditEntryResponses(entries))
+}
+
+// auditQuery reads the filter and limit of an /audit request.
+func auditQuery(r *http.Request) (filter database.AuditFilter, limit int, err error) {
+	query := r.URL.Query()
+
+	filter.Entity = query.Get("entity")
+	switch filter.Entity {
+	case "", database.AuditEntityContact, database.AuditEntityAddress:
+	default:
+		return filter, 0, &invalidRequest{message: "entity must be contact or address"}
+	}
+	filter.Action = query.Get("action")
+	switch filter.Action {
+	case "", database.AuditActionCreate, database.AuditActionUpdate, database.AuditActionDelete, database.AuditActionMerge:
+	default:
+		return filter, 0, &invalidRequest{message: "action must be create, update, delete or merge"}
+	}
+	filter.Actor = query.Get("actor")
+	filter.RequestID = query.Get("requestId")
+
+	if filter.EntityID, err = queryID(query, "entityId"); err != nil {
+		return filter, 0, err
+	}
+	if filter.ContactID, err = queryID(query, "contactId"); err != nil {
+		return filter, 0, err
+	}
+	if filter.BeforeID, err = queryID(query, "before"); err != nil {
+		return filter, 0, err
+	}
+	if filter.Since, err = queryTime(query, "since"); err != nil {
+		return filter, 0, err
+	}
+	if filter.Until, err = queryTime(query, "until"); err != nil {
+		return filter, 0, err
+	}
+
+	limit, err = auditLimit(query)
+	return filter, limit, err
+}
+
+func auditLimit(query url.Values) (int, error) {
+	value := query.Get("limit")
+	if value == "" {
+		return defaultAuditLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 || limit > maxAuditLimit {
+		return 0, &invalidRequest{message: fmt.Sprintf("limit must be a number from 1 to %d", maxAuditLimit)}
+	}
+	return limit, nil
+}
+
+// queryID reads an optional positive ID, returning 0 if it's missing.
+func queryID(query url.Values, name string) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, &invalidRequest{message: fmt.Sprintf("%s %q must be a positive number", name, value)}
+	}
+	return id, nil
+}
+
+// queryTime reads an optional time in milliseconds since the epoch, the form of the API's
+// timestamps, returning the zero time if it's missing.
+func queryTime(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, &invalidRequest{message: fmt.Sprintf("%s %q must be a time in milliseconds since the epoch", name, value)}
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)), nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+func TestAuditQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantFilter database.AuditFilter
+		wantLimit  int
+		wantErr    bool
+	}{
+		{query: "", wantLimit: defaultAuditLimit},
+		{
+			query:      "entity=address&entityId=2&contactId=1&action=delete&actor=user-1&requestId=abc&before=50&limit=10",
+			wantFilter: database.AuditFilter{ContactID: 1, Entity: "address", EntityID: 2, Action: "delete", Actor: "user-1", RequestID: "abc", BeforeID: 50},
+			wantLimit:  10,
+		},
+		{
+			query:      "since=1554441489907&until=1554441490000",
+			wantFilter: database.AuditFilter{Since: time.Unix(1554441489, 907000000), Until: time.Unix(1554441490, 0)},
+			wantLimit:  defaultAuditLimit,
+		},
+		{query: "entity=email", wantErr: true},
+		{query: "action=read", wantErr: true},
+		{query: "entityId=0", wantErr: true},
+		{query: "contactId=one", wantErr: true},
+		{query: "since=yesterday", wantErr: true},
+		{query: "limit=1001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		r := httptest.NewRequest("GET", "/api/v1/audit?"+tt.query, nil)
+
+		// act
+		filter, limit, err := auditQuery(r)
+
+		// assert
+		if tt.wantErr {
+			if !isInvalidRequest(err) {
+				t.Errorf("%q: error, want: invalid request got: %v", tt.query, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: error, want: nil got: %v", tt.query, err)
+		}
+		if filter != tt.wantFilter || limit != tt.wantLimit {
+			t.Errorf("%q: want: %+v %d got: %+v %d", tt.query, tt.wantFilter, tt.wantLimit, filter, limit)
+		}
+	}
+}
+
+func TestOk_AuditEntryEmbedsBeforeAndAfterAsObjects(t *testing.T) {
+	// arrange
+	entries := []models.AuditEntryResponse{
+		{ID: 1, Action: "update", Entity: "contact", EntityID: 1, Before: `{"firstName":"John"}`, After: `{"firstName":"Jane"}`},
+		{ID: 2, Action: "delete", Entity: "contact", EntityID: 1, Before: `{"firstName":"Jane"}`},
+	}
+
+	// act
+	rec := serveWithAccept("application/json", entries)
+
+	// assert
+	body := rec.Body.String()
+	if !strings.Contains(body, `"before":{"firstName":"John"},"after":{"firstName":"Jane"}`) {
+		t.Errorf("update, want: before and after objects got: %s", body)
+	}
+	if strings.Count(body, `"after"`) != 1 {
+		t.Errorf("delete, want: after left out got: %s", body)
+	}
+}
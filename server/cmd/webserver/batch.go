@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+// batchMaxOperations bounds the work, and the time the transaction is held open, for one request.
+const batchMaxOperations = 100
+
+// batchResult is what an operation produced, for the response and for later references to it.
+type batchResult struct {
+	contact   *database.Contact
+	addresses []database.Address // the contact's
+	address   *database.Address
+}
+
+// batchFailed is returned from the transaction when an operation fails.
+type batchFailed struct {
+	index int
+	err   error
+}
+
+func (e *batchFailed) Error() string {
+	return fmt.Sprintf("operation %d failed: %v", e.index, e.err)
+}
+
+// @Summary Create, update and delete contacts and addresses in one transaction
+// @Description Operations run in order. An operation's id or contactId may refer to the result of an
+// @Description earlier one, e.g. "$0.id" is the ID created by the first operation and "$1.contactId" the
+// @Description contact of the address in the second. If any operation fails nothing is saved; the response
+// @Description has that operation's status and error, and a 424 for every other operation.
+// @Param batch body models.BatchRequest true "Operations"
+// @Accept json,application/xml,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.BatchResponse
+// @Failure 400 {object} models.BatchResponse
+// @Failure 404 {object} models.BatchResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /batch [post]
+func (ws *webserver) handleBatch(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// create var ready to hold decoded body
+	var request models.BatchRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+	if len(request.Operations) == 0 {
+		return &invalidRequest{message: "operations must not be empty"}
+	}
+	if len(request.Operations) > batchMaxOperations {
+		return &invalidRequest{message: fmt.Sprintf("operations must not contain more than %d operations", batchMaxOperations)}
+	}
+
+	// run the operations
+	results := make([]batchResult, 0, len(request.Operations))
+	err := db.Transaction(func(tx database.DB) error {
+		for i, op := range request.Operations {
+			result, err := runBatchOperation(tx, op, results)
+			if err != nil {
+				return &batchFailed{index: i, err: err}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+
+	failed, ok := err.(*batchFailed)
+	if err != nil && !ok {
+		return err
+	}
+
+	// create response
+	response := models.BatchResponse{
+		Committed: err == nil,
+		Results:   make([]models.BatchResultResponse, len(request.Operations)),
+	}
+	if failed == nil {
+		for i, result := range results {
+			response.Results[i] = mapBatchResult(result)
+		}
+		return Ok(w, response)
+	}
+
+	status := httpStatus(failed.err)
+	for i := range response.Results {
+		response.Results[i] = models.BatchResultResponse{
+			Status: http.StatusFailedDependency,
+			Error:  fmt.Sprintf("not applied because operation %d failed", failed.index),
+		}
+	}
+	response.Results[failed.index] = models.BatchResultResponse{Status: status, Error: failed.err.Error()}
+	response.Error = failed.Error()
+
+	return Respond(w, status, response)
+}
+
+// runBatchOperation runs op through the providers, resolving its references against the results
+// of the operations before it.
+func runBatchOperation(db database.DB, op models.BatchOperation, results []batchResult) (batchResult, error) {
+	switch op.Type {
+	case "contact":
+		return runContactOperation(db, op, results)
+	case "address":
+		return runAddressOperation(db, op, results)
+	}
+	return batchResult{}, &invalidRequest{message: `type must be "contact" or "address"`}
+}
+
+func runContactOperation(db database.DB, op models.BatchOperation, results []batchResult) (batchResult, error) {
+	if op.Op != "create" && op.Op != "update" && op.Op != "delete" {
+		return batchResult{}, &invalidRequest{message: `op must be "create", "update" or "delete"`}
+	}
+	if op.Op != "delete" && op.Contact == nil {
+		return batchResult{}, &invalidRequest{message: "contact is required"}
+	}
+
+	if op.Op == "create" {
+		contact, addresses, err := db.Contacts.CreateWithAddresses(models.MapCreateContactRequest(*op.Contact), models.MapCreateAddressRequests(0, op.Contact.Addresses))
+		return batchResult{contact: &contact, addresses: addresses}, err
+	}
+
+	id, err := resolveBatchID("id", op.ID, results)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	if op.Op == "update" {
+		contact, addresses, err := db.Contacts.UpdateWithAddresses(models.MapUpdateContactRequest(id, *op.Contact), models.MapCreateAddressRequests(id, op.Contact.Addresses))
+		return batchResult{contact: &contact, addresses: addresses}, err
+	}
+	return batchResult{}, db.Contacts.Delete(id)
+}
+
+func runAddressOperation(db database.DB, op models.BatchOperation, results []batchResult) (batchResult, error) {
+	if op.Op != "create" && op.Op != "update" && op.Op != "delete" {
+		return batchResult{}, &invalidRequest{message: `op must be "create", "update" or "delete"`}
+	}
+	if op.Op != "delete" && op.Address == nil {
+		return batchResult{}, &invalidRequest{message: "address is required"}
+	}
+
+	contactID, err := resolveBatchID("contactId", op.ContactID, results)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	if op.Op == "create" {
+		// ensure the contact exists
+		if _, err := db.Contacts.Get(contactID); err != nil {
+			return batchResult{}, err
+		}
+		address, err := db.Addresses.Create(models.MapCreateAddressRequest(contactID, *op.Address))
+		return batchResult{address: &address}, err
+	}
+
+	addressID, err := resolveBatchID("id", op.ID, results)
+	if err != nil {
+		return batchResult{}, err
+	}
+
+	// ensure address belongs to contact
+	address, err := db.Addresses.Get(addressID)
+	if err != nil {
+		return batchResult{}, err
+	}
+	if address.ContactID != contactID {
+		return batchResult{}, &notFound{}
+	}
+
+	if op.Op == "update" {
+		address, err := db.Addresses.Update(models.MapUpdateAddressRequest(contactID, addressID, *op.Address))
+		return batchResult{address: &address}, err
+	}
+	return batchResult{}, db.Addresses.Delete(addressID)
+}
+
+// resolveBatchID parses the value of field, which is either an ID or a reference such as $0.id to
+// the id or contactId of an earlier operation's result.
+func resolveBatchID(field, value string, results []batchResult) (int, error) {
+	if value == "" {
+		return 0, &invalidRequest{message: field + " is required"}
+	}
+	if !strings.HasPrefix(value, "$") {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, &invalidRequest{message: fmt.Sprintf("%s must be an ID or a reference such as $0.id, not %q", field, value)}
+		}
+		return id, nil
+	}
+
+	parts := strings.SplitN(value[1:], ".", 2)
+	index, err := strconv.Atoi(parts[0])
+	if err != nil || len(parts) != 2 {
+		return 0, &invalidRequest{message: fmt.Sprintf("%s reference %q must look like $0.id", field, value)}
+	}
+	if index < 0 || index >= len(results) {
+		return 0, &invalidRequest{message: fmt.Sprintf("%s reference %q must refer to an earlier operation", field, value)}
+	}
+
+	result := results[index]
+	switch {
+	case parts[1] == "id" && result.contact != nil:
+		return result.contact.ID, nil
+	case parts[1] == "id" && result.address != nil:
+		return result.address.ID, nil
+	case parts[1] == "contactId" && result.address != nil:
+		return result.address.ContactID, nil
+	}
+	return 0, &invalidRequest{message: fmt.Sprintf("%s reference %q refers to a field operation %d didn't return", field, value, index)}
+}
+
+func mapBatchResult(result batchResult) models.BatchResultResponse {
+	response := models.BatchResultResponse{Status: http.StatusOK}
+	if result.contact != nil {
+		contact := models.MapContactResponse(*result.contact, result.addresses)
+		response.Contact = &contact
+	}
+	if result.address != nil {
+		address := models.MapContactAddress(*result.address)
+		response.Address = &address
+	}
+	return response
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+func TestResolveBatchID(t *testing.T) {
+	results := []batchResult{
+		{contact: &database.Contact{ID: 7}},
+		{address: &database.Address{ID: 9, ContactID: 7}},
+	}
+
+	tests := []struct {
+		value     string
+		want      int
+		wantError string
+	}{
+		{value: "12", want: 12},
+		{value: "$0.id", want: 7},
+		{value: "$1.id", want: 9},
+		{value: "$1.contactId", want: 7},
+		{value: "", wantError: "id is required"},
+		{value: "abc", wantError: `id must be an ID or a reference such as $0.id, not "abc"`},
+		{value: "$0", wantError: `id reference "$0" must look like $0.id`},
+		{value: "$x.id", wantError: `id reference "$x.id" must look like $0.id`},
+		{value: "$2.id", wantError: `id reference "$2.id" must refer to an earlier operation`},
+		{value: "$0.contactId", wantError: `id reference "$0.contactId" refers to a field operation 0 didn't return`},
+	}
+
+	for _, tt := range tests {
+		// act
+		got, err := resolveBatchID("id", tt.value, results)
+
+		// assert
+		if tt.wantError != "" {
+			if err == nil || err.Error() != tt.wantError || !isInvalidRequest(err) {
+				t.Errorf("%q: error, want: %q got: %v", tt.value, tt.wantError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: id, want: %d got: %d", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestRunBatchOperation_RejectsInvalidOperations(t *testing.T) {
+	tests := []struct {
+		op        models.BatchOperation
+		wantError string
+	}{
+		{op: models.BatchOperation{Op: "create", Type: "note"}, wantError: `type must be "contact" or "address"`},
+		{op: models.BatchOperation{Op: "upsert", Type: "contact"}, wantError: `op must be "create", "update" or "delete"`},
+		{op: models.BatchOperation{Op: "create", Type: "contact"}, wantError: "contact is required"},
+		{op: models.BatchOperation{Op: "update", Type: "address", ContactID: "1"}, wantError: "address is required"},
+		{op: models.BatchOperation{Op: "delete", Type: "address"}, wantError: "contactId is required"},
+		{op: models.BatchOperation{Op: "delete", Type: "contact", ID: "$0.id"}, wantError: `id reference "$0.id" must refer to an earlier operation`},
+	}
+
+	for _, tt := range tests {
+		// act; each is rejected before the database is used
+		_, err := runBatchOperation(database.DB{}, tt.op, nil)
+
+		// assert
+		if err == nil || err.Error() != tt.wantError {
+			t.Errorf("%+v: error, want: %q got: %v", tt.op, tt.wantError, err)
+		}
+	}
+}
@@ -0,0 +1,660 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+)
+
+// @Summary Get all of a contact's emails
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.EmailResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/emails [get]
+func (ws *webserver) handleGetContactEmails(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get contact emails
+	emails, err := db.Emails.GetAllByContactID(contactID)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactEmails(emails)
+
+	return Ok(w, response)
+}
+
+// @Summary Get a contact email
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.EmailResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param emailID path int true "Email ID"
+// @Router /contacts/{contactID}/emails/{emailID} [get]
+func (ws *webserver) handleGetContactEmail(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	emailID, err := strconv.Atoi(vars["emailID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get email
+	email, err := db.Emails.Get(emailID)
+	if err != nil {
+		return err
+	}
+	// ensure email belongs to contact
+	if email.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create response
+	response := models.MapContactEmail(email)
+
+	return Ok(w, response)
+}
+
+// @Summary Create a contact email
+// @Param email body models.EmailRequest true "Create email"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.EmailResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/emails [post]
+func (ws *webserver) handlePostContactEmails(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.EmailRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// create email
+	create := models.MapEmailRequest(contactID, 0, request)
+	email, err := db.Emails.Create(create)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactEmail(email)
+
+	return Ok(w, response)
+}
+
+// @Summary Update a contact email
+// @Param email body models.EmailRequest true "Update email"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.EmailResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param emailID path int true "Email ID"
+// @Router /contacts/{contactID}/emails/{emailID} [put]
+func (ws *webserver) handlePutContactEmail(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	emailID, err := strconv.Atoi(vars["emailID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get email
+	email, err := db.Emails.Get(emailID)
+	if err != nil {
+		return err
+	}
+	// ensure email belongs to contact
+	if email.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.EmailRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// update email
+	update := models.MapEmailRequest(contactID, emailID, request)
+	updated, err := db.Emails.Update(update)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactEmail(updated)
+
+	return Ok(w, response)
+}
+
+// @Summary Delete a contact email
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {string} string "{}"
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param emailID path int true "Email ID"
+// @Router /contacts/{contactID}/emails/{emailID} [delete]
+func (ws *webserver) handleDeleteContactEmail(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	emailID, err := strconv.Atoi(vars["emailID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get email
+	email, err := db.Emails.Get(emailID)
+	if err != nil {
+		return err
+	}
+	// ensure email belongs to contact
+	if email.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// delete email
+	if err = db.Emails.Delete(emailID); err != nil {
+		return err
+	}
+
+	// struct{}{} is an empty object, returns "{}" to the client
+	return Ok(w, struct{}{})
+}
+
+// @Summary Get all of a contact's phones
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.PhoneResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/phones [get]
+func (ws *webserver) handleGetContactPhones(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get contact phones
+	phones, err := db.Phones.GetAllByContactID(contactID)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactPhones(phones)
+
+	return Ok(w, response)
+}
+
+// @Summary Get a contact phone
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.PhoneResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param phoneID path int true "Phone ID"
+// @Router /contacts/{contactID}/phones/{phoneID} [get]
+func (ws *webserver) handleGetContactPhone(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	phoneID, err := strconv.Atoi(vars["phoneID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get phone
+	phone, err := db.Phones.Get(phoneID)
+	if err != nil {
+		return err
+	}
+	// ensure phone belongs to contact
+	if phone.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create response
+	response := models.MapContactPhone(phone)
+
+	return Ok(w, response)
+}
+
+// @Summary Create a contact phone
+// @Param phone body models.PhoneRequest true "Create phone"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.PhoneResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/phones [post]
+func (ws *webserver) handlePostContactPhones(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.PhoneRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// create phone
+	create := models.MapPhoneRequest(contactID, 0, request)
+	phone, err := db.Phones.Create(create)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactPhone(phone)
+
+	return Ok(w, response)
+}
+
+// @Summary Update a contact phone
+// @Param phone body models.PhoneRequest true "Update phone"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.PhoneResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param phoneID path int true "Phone ID"
+// @Router /contacts/{contactID}/phones/{phoneID} [put]
+func (ws *webserver) handlePutContactPhone(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	phoneID, err := strconv.Atoi(vars["phoneID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get phone
+	phone, err := db.Phones.Get(phoneID)
+	if err != nil {
+		return err
+	}
+	// ensure phone belongs to contact
+	if phone.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.PhoneRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// update phone
+	update := models.MapPhoneRequest(contactID, phoneID, request)
+	updated, err := db.Phones.Update(update)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactPhone(updated)
+
+	return Ok(w, response)
+}
+
+// @Summary Delete a contact phone
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {string} string "{}"
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param phoneID path int true "Phone ID"
+// @Router /contacts/{contactID}/phones/{phoneID} [delete]
+func (ws *webserver) handleDeleteContactPhone(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	phoneID, err := strconv.Atoi(vars["phoneID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get phone
+	phone, err := db.Phones.Get(phoneID)
+	if err != nil {
+		return err
+	}
+	// ensure phone belongs to contact
+	if phone.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// delete phone
+	if err = db.Phones.Delete(phoneID); err != nil {
+		return err
+	}
+
+	// struct{}{} is an empty object, returns "{}" to the client
+	return Ok(w, struct{}{})
+}
+
+// @Summary Get all of a contact's tags
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.TagResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/tags [get]
+func (ws *webserver) handleGetContactTags(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get contact tags
+	tags, err := db.Tags.GetAllByContactID(contactID)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactTags(tags)
+
+	return Ok(w, response)
+}
+
+// @Summary Get a contact tag
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.TagResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param tagID path int true "Tag ID"
+// @Router /contacts/{contactID}/tags/{tagID} [get]
+func (ws *webserver) handleGetContactTag(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	tagID, err := strconv.Atoi(vars["tagID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get tag
+	tag, err := db.Tags.Get(tagID)
+	if err != nil {
+		return err
+	}
+	// ensure tag belongs to contact
+	if tag.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create response
+	response := models.MapContactTag(tag)
+
+	return Ok(w, response)
+}
+
+// @Summary Create a contact tag
+// @Param tag body models.TagRequest true "Create tag"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.TagResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/tags [post]
+func (ws *webserver) handlePostContactTags(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.TagRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// create tag
+	create := models.MapTagRequest(contactID, 0, request)
+	tag, err := db.Tags.Create(create)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactTag(tag)
+
+	return Ok(w, response)
+}
+
+// @Summary Update a contact tag
+// @Param tag body models.TagRequest true "Update tag"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.TagResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param tagID path int true "Tag ID"
+// @Router /contacts/{contactID}/tags/{tagID} [put]
+func (ws *webserver) handlePutContactTag(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	tagID, err := strconv.Atoi(vars["tagID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get tag
+	tag, err := db.Tags.Get(tagID)
+	if err != nil {
+		return err
+	}
+	// ensure tag belongs to contact
+	if tag.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create var ready to hold decoded body
+	var request models.TagRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// update tag
+	update := models.MapTagRequest(contactID, tagID, request)
+	updated, err := db.Tags.Update(update)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactTag(updated)
+
+	return Ok(w, response)
+}
+
+// @Summary Delete a contact tag
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {string} string "{}"
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param tagID path int true "Tag ID"
+// @Router /contacts/{contactID}/tags/{tagID} [delete]
+func (ws *webserver) handleDeleteContactTag(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	tagID, err := strconv.Atoi(vars["tagID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get tag
+	tag, err := db.Tags.Get(tagID)
+	if err != nil {
+		return err
+	}
+	// ensure tag belongs to contact
+	if tag.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// delete tag
+	if err = db.Tags.Delete(tagID); err != nil {
+		return err
+	}
+
+	// struct{}{} is an empty object, returns "{}" to the client
+	return Ok(w, struct{}{})
+}
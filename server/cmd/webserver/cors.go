@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsConfig controls which browser origins may call the API directly.
+type corsConfig struct {
+	allowedOrigins   []string // exact origins such as http://localhost:3000, or * for any
+	allowedMethods   []string
+	allowedHeaders   []string // request headers a client may send
+	exposedHeaders   []string // response headers a client may read
+	allowCredentials bool     // allow cookies and Authorization; * then echoes the origin
+	maxAge           time.Duration
+}
+
+// corsPrefix is the part of the API CORS applies to. /metrics and /log/level stay same-origin.
+const corsPrefix = "/api/v1/"
+
+func (c corsConfig) allowsOrigin(origin string) bool {
+	for _, allowed := range c.allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func (c corsConfig) allowsMethod(method string) bool {
+	for _, allowed := range c.allowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowCORS adds CORS headers to /api/v1 responses for allowed origins and answers preflight requests.
+// It wraps the router so every path gets them, including ones that end in a 404 or 405, and a
+// preflight never reaches the router at all.
+func (ws *webserver) allowCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if !strings.HasPrefix(r.URL.Path, corsPrefix) || origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the response depends on the Origin, so caches must not share it between origins
+		w.Header().Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			// answered here rather than routed; the browser enforces the outcome from the
+			// headers, so a disallowed preflight still gets a 204, just without them
+			ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+				if ws.cors.allowsOrigin(origin) && ws.cors.allowsMethod(r.Header.Get("Access-Control-Request-Method")) {
+					ws.writePreflightHeaders(w, r, origin)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return nil
+			}).ServeHTTP(w, r)
+			return
+		}
+
+		if ws.cors.allowsOrigin(origin) {
+			ws.writeOriginHeaders(w, origin)
+			if len(ws.cors.exposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(ws.cors.exposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ws *webserver) writeOriginHeaders(w http.ResponseWriter, origin string) {
+	// a wildcard can't be combined with credentials, so echo the origin instead
+	allowOrigin := origin
+	if !ws.cors.allowCredentials && len(ws.cors.allowedOrigins) == 1 && ws.cors.allowedOrigins[0] == "*" {
+		allowOrigin = "*"
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+	if ws.cors.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (ws *webserver) writePreflightHeaders(w http.ResponseWriter, r *http.Request, origin string) {
+	ws.writeOriginHeaders(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(ws.cors.allowedMethods, ", "))
+
+	allowedHeaders := strings.Join(ws.cors.allowedHeaders, ", ")
+	if allowedHeaders == "*" {
+		// with credentials * is taken literally, so allow exactly what was asked for
+		allowedHeaders = r.Header.Get("Access-Control-Request-Headers")
+	}
+	if allowedHeaders != "" {
+		w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+	}
+
+	if ws.cors.maxAge > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(ws.cors.maxAge.Seconds())))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newCORSTestHandler(cors corsConfig) http.Handler {
+	ws := newTestWebserver()
+	ws.cors = cors
+
+	r := mux.NewRouter()
+	r.NotFoundHandler = ws.handler(notFoundHandler)
+	r.HandleFunc("/api/v1/ping", ws.handler(ws.handlePing)).Methods("GET")
+	return chain(r, ws.allowCORS)
+}
+
+var testCORSConfig = corsConfig{
+	allowedOrigins: []string{"http://localhost:3000"},
+	allowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+	allowedHeaders: []string{"Content-Type"},
+	exposedHeaders: []string{"X-Request-ID"},
+	maxAge:         10 * time.Minute,
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	// arrange
+	h := newCORSTestHandler(testCORSConfig)
+	req := httptest.NewRequest("OPTIONS", "/api/v1/contacts/1", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("status, want: %d got: %d", http.StatusNoContent, rec.Code)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":  "http://localhost:3000",
+		"Access-Control-Allow-Methods": "GET, POST, PUT, DELETE",
+		"Access-Control-Allow-Headers": "Content-Type",
+		"Access-Control-Max-Age":       "600",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s, want: %q got: %q", header, want, got)
+		}
+	}
+}
+
+func TestCORS_PreflightFromDisallowedOriginGetsNoHeaders(t *testing.T) {
+	// arrange
+	h := newCORSTestHandler(testCORSConfig)
+	req := httptest.NewRequest("OPTIONS", "/api/v1/ping", nil)
+	req.Header.Set("Origin", "https://evil.example")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin, want: none got: %q", got)
+	}
+}
+
+func TestCORS_HeadersOnNotFound(t *testing.T) {
+	// arrange
+	h := newCORSTestHandler(testCORSConfig)
+	req := httptest.NewRequest("GET", "/api/v1/nothing", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status, want: %d got: %d", http.StatusNotFound, rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
+		t.Errorf("Access-Control-Allow-Origin, want: %q got: %q", "http://localhost:3000", got)
+	}
+	if got := rec.Header().Get("Access-Control-Expose-Headers"); got != "X-Request-ID" {
+		t.Errorf("Access-Control-Expose-Headers, want: %q got: %q", "X-Request-ID", got)
+	}
+}
+
+func TestCORS_WildcardWithCredentialsEchoesOrigin(t *testing.T) {
+	// arrange
+	config := testCORSConfig
+	config.allowedOrigins = []string{"*"}
+	config.allowCredentials = true
+	h := newCORSTestHandler(config)
+
+	req := httptest.NewRequest("GET", "/api/v1/ping", nil)
+	req.Header.Set("Origin", "https://app.example")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+		t.Errorf("Access-Control-Allow-Origin, want: %q got: %q", "https://app.example", got)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Access-Control-Allow-Credentials, want: %q got: %q", "true", got)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// csvColumn is a scalar struct field written as a CSV column, named by its json tag. Fields of
+// nested structs are named parent.child; slices can't be flattened and are left out.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+func csvColumns(t reflect.Type, prefix string, parent []int) []csvColumn {
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		index := append(append([]int{}, parent...), i)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			columns = append(columns, csvColumns(fieldType, prefix+name+".", index)...)
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+		default:
+			columns = append(columns, csvColumn{name: prefix + name, index: index})
+		}
+	}
+	return columns
+}
+
+// marshalCSV encodes a slice of structs as a header row followed by a row per element. Anything
+// else isn't a table, so it's unsupported and the client gets another format or a 406.
+func marshalCSV(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice || value.Type().Elem().Kind() != reflect.Struct {
+		return nil, errUnsupportedValue
+	}
+	columns := csvColumns(value.Type().Elem(), "", nil)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.name
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(columns))
+	for row := 0; row < value.Len(); row++ {
+		for i, column := range columns {
+			record[i] = csvCell(value.Index(row), column.index)
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvCell formats the field at index, leaving it empty if a nil pointer is in the way.
+func csvCell(v reflect.Value, index []int) string {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return ""
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// decodeCSVBody decodes a header row and a single record into a struct, matching columns to
+// fields by their json names. An empty cell leaves a pointer field nil.
+func decodeCSVBody(r io.Reader, v interface{}) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		if _, ok := err.(*csv.ParseError); ok {
+			return &invalidRequest{message: "body contains badly-formed CSV: " + err.Error()}
+		}
+		return decodeError(err)
+	}
+	if len(records) != 2 {
+		return &invalidRequest{message: "body must contain a header row and exactly one record"}
+	}
+
+	target := reflect.ValueOf(v).Elem()
+	byName := make(map[string]csvColumn)
+	for _, column := range csvColumns(target.Type(), "", nil) {
+		byName[column.name] = column
+	}
+
+	for i, name := range records[0] {
+		column, ok := byName[name]
+		if !ok {
+			return &invalidRequest{message: fmt.Sprintf("body contains unknown column %q", name)}
+		}
+		if err := setCSVField(target, column.index, records[1][i]); err != nil {
+			return &invalidRequest{message: fmt.Sprintf("body contains an invalid value for column %q", name)}
+		}
+	}
+	return nil
+}
+
+func setCSVField(v reflect.Value, index []int, cell string) error {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	if v.Kind() == reflect.Ptr {
+		if cell == "" {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(cell)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(cell, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(cell, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(cell, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("can't decode %s from CSV", v.Type())
+	}
+	return nil
+}
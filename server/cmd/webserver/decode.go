@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// decode decodes the request body into v in the format named by its Content-Type, see formats.
+// The body must be no larger than maxBodyBytes and hold a single value with no fields v doesn't
+// have. Failures are returned as errors the client can act on, e.g.
+//
+//	body contains badly-formed JSON (at offset 14)
+//	body contains an invalid value for field "line1" (at offset 12)
+func (ws *webserver) decode(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return unsupportedContentType()
+	}
+	f := formatFor(mediaType)
+	if f == nil {
+		return unsupportedContentType()
+	}
+
+	if ws.maxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, ws.maxBodyBytes)
+	}
+	return f.decode(r.Body, v)
+}
+
+func decodeJSONBody(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+
+	// a second value, or garbage after the first, means the client sent something other than
+	// what it meant to
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &requestTooLarge{limit: tooLarge.Limit}
+		}
+		return &invalidRequest{message: "body must only contain a single JSON value"}
+	}
+	return nil
+}
+
+// decodeError describes why a json.Decoder failed in terms of the request body.
+func decodeError(err error) error {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		tooLargeErr *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &tooLargeErr):
+		return &requestTooLarge{limit: tooLargeErr.Limit}
+	case errors.As(err, &syntaxErr):
+		return &invalidRequest{message: fmt.Sprintf("body contains badly-formed JSON (at offset %d)", syntaxErr.Offset)}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &invalidRequest{message: "body contains badly-formed JSON"}
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return &invalidRequest{message: fmt.Sprintf("body must be a JSON %s, not %s", jsonType(typeErr), typeErr.Value)}
+		}
+		return &invalidRequest{message: fmt.Sprintf("body contains an invalid value for field %q (at offset %d)", typeErr.Field, typeErr.Offset)}
+	case errors.Is(err, io.EOF):
+		return &invalidRequest{message: "body must not be empty"}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for this
+		return &invalidRequest{message: "body contains unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")}
+	default:
+		return &invalidRequest{message: err.Error()}
+	}
+}
+
+// jsonType names the JSON type a Go type is decoded from.
+func jsonType(err *json.UnmarshalTypeError) string {
+	switch err.Type.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+)
+
+func TestDecode_JSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantError   string
+	}{
+		{name: "valid", contentType: "application/json; charset=utf-8", body: `{"line1":"1 Main St"}`, wantStatus: http.StatusOK},
+		{name: "wrong content type", contentType: "text/plain", body: `{}`, wantStatus: http.StatusUnsupportedMediaType, wantError: "Content-Type must be one of application/json, application/xml, text/csv, application/msgpack"},
+		{name: "missing content type", body: `{}`, wantStatus: http.StatusUnsupportedMediaType, wantError: "Content-Type must be one of application/json, application/xml, text/csv, application/msgpack"},
+		{name: "syntax error", contentType: "application/json", body: `{"line1": "a",}`, wantStatus: http.StatusBadRequest, wantError: "body contains badly-formed JSON (at offset 15)"},
+		{name: "truncated", contentType: "application/json", body: `{"line1": "a"`, wantStatus: http.StatusBadRequest, wantError: "body contains badly-formed JSON"},
+		{name: "wrong type", contentType: "application/json", body: `{"line1": 12}`, wantStatus: http.StatusBadRequest, wantError: `body contains an invalid value for field "line1" (at offset 12)`},
+		{name: "not an object", contentType: "application/json", body: `[]`, wantStatus: http.StatusBadRequest, wantError: "body must be a JSON object, not array"},
+		{name: "unknown field", contentType: "application/json", body: `{"line3": "a"}`, wantStatus: http.StatusBadRequest, wantError: `body contains unknown field "line3"`},
+		{name: "empty", contentType: "application/json", body: ``, wantStatus: http.StatusBadRequest, wantError: "body must not be empty"},
+		{name: "trailing data", contentType: "application/json", body: `{} {}`, wantStatus: http.StatusBadRequest, wantError: "body must only contain a single JSON value"},
+		{name: "too large", contentType: "application/json", body: `{"line1":"` + strings.Repeat("a", 100) + `"}`, wantStatus: http.StatusRequestEntityTooLarge, wantError: "request body is larger than 64 bytes"},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		ws := newTestWebserver()
+		ws.maxBodyBytes = 64
+		h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+			var request models.AddressRequest
+			if err := ws.decode(w, r, &request); err != nil {
+				return err
+			}
+			return Ok(w, request)
+		})
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rec := httptest.NewRecorder()
+
+		// act
+		h.ServeHTTP(rec, req)
+
+		// assert
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status, want: %d got: %d", tt.name, tt.wantStatus, rec.Code)
+		}
+		if tt.wantError == "" {
+			continue
+		}
+		var body models.ErrorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if body.Error != tt.wantError {
+			t.Errorf("%s: Error, want: %q got: %q", tt.name, tt.wantError, body.Error)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+)
+
+const (
+	// defaultMinNameSimilarity is the name similarity /contacts/duplicates looks for when none is given.
+	defaultMinNameSimilarity = 0.5
+	// defaultDuplicatesLimit and maxDuplicatesLimit bound the pairs /contacts/duplicates returns.
+	defaultDuplicatesLimit = 100
+	maxDuplicatesLimit     = 1000
+)
+
+// @Summary Find likely duplicate contacts
+// @Description Returns pairs of contacts with similar names, by trigram similarity, or that share an address or
+// @Description an email address. Pairs with the most in common come first, then the most similar names.
+// @Param minSimilarity query number false "The lowest name similarity to report, from 0.3 to 1" default(0.5)
+// @Param limit query int false "The most pairs to return, up to 1000" default(100)
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.DuplicateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /contacts/duplicates [get]
+func (ws *webserver) handleGetDuplicateContacts(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get query params
+	minSimilarity, limit, err := duplicatesQuery(r)
+	if err != nil {
+		return err
+	}
+
+	pairs, err := db.Contacts.FindDuplicates(minSimilarity, limit)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, models.MapDuplicateResponses(pairs))
+}
+
+// duplicatesQuery reads the similarity and limit of a /contacts/duplicates request. The database
+// checks the similarity's range.
+func duplicatesQuery(r *http.Request) (minSimilarity float64, limit int, err error) {
+	query := r.URL.Query()
+
+	minSimilarity = defaultMinNameSimilarity
+	if value := query.Get("minSimilarity"); value != "" {
+		if minSimilarity, err = queryFloat(value, "minSimilarity"); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	limit = defaultDuplicatesLimit
+	if value := query.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxDuplicatesLimit {
+			return 0, 0, &invalidRequest{message: fmt.Sprintf("limit must be a number from 1 to %d", maxDuplicatesLimit)}
+		}
+	}
+
+	return minSimilarity, limit, nil
+}
+
+// @Summary Merge a contact into another
+// @Description Moves the source contact's addresses, emails, phones and tags to this contact and deletes the
+// @Description source, in one transaction. Details this contact already has are dropped rather than copied,
+// @Description and its empty company, title and notes are taken from the source. The merge is recorded in
+// @Description the audit log.
+// @Param contactID path int true "ID of the contact to keep"
+// @Param merge body models.MergeRequest true "The contact to merge into it"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.ContactResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /contacts/{contactID}/merge [post]
+func (ws *webserver) handleMergeContact(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded json from body
+	var request models.MergeRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+	if request.SourceID == 0 {
+		return &invalidRequest{message: "sourceId is required"}
+	}
+
+	// merge the source into the contact
+	contact, err := db.Contacts.Merge(id, request.SourceID)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response, err := contactDetailsResponse(db, contact)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, response)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDuplicatesQuery(t *testing.T) {
+	tests := []struct {
+		query             string
+		wantMinSimilarity float64
+		wantLimit         int
+		wantErr           bool
+	}{
+		{query: "", wantMinSimilarity: defaultMinNameSimilarity, wantLimit: defaultDuplicatesLimit},
+		{query: "minSimilarity=0.8&limit=5", wantMinSimilarity: 0.8, wantLimit: 5},
+		{query: "minSimilarity=high", wantErr: true},
+		{query: "limit=0", wantErr: true},
+		{query: "limit=1001", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		r := httptest.NewRequest("GET", "/api/v1/contacts/duplicates?"+tt.query, nil)
+
+		// act
+		minSimilarity, limit, err := duplicatesQuery(r)
+
+		// assert
+		if tt.wantErr {
+			if !isInvalidRequest(err) {
+				t.Errorf("%q: error, want: invalid request got: %v", tt.query, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: error, want: nil got: %v", tt.query, err)
+		}
+		if minSimilarity != tt.wantMinSimilarity || limit != tt.wantLimit {
+			t.Errorf("%q: want: %v %d got: %v %d", tt.query, tt.wantMinSimilarity, tt.wantLimit, minSimilarity, limit)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import "fmt"
+
+type invalidRequest struct {
+	message string
+}
+
+func (e *invalidRequest) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "invalid request"
+}
+
+type notFound struct {
+	message string
+}
+
+func (e *notFound) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "not found"
+}
+
+type unauthorized struct {
+	message string
+}
+
+func (e *unauthorized) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "unauthorized"
+}
+
+type tooManyRequests struct {
+	message string
+}
+
+func (e *tooManyRequests) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "too many requests"
+}
+
+type methodNotAllowed struct {
+	message string
+}
+
+func (e *methodNotAllowed) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "method not allowed"
+}
+
+type requestTooLarge struct {
+	limit int64
+}
+
+func (e *requestTooLarge) Error() string {
+	return fmt.Sprintf("request body is larger than %d bytes", e.limit)
+}
+
+type unsupportedMediaType struct {
+	message string
+}
+
+func (e *unsupportedMediaType) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "unsupported media type"
+}
+
+type notAcceptable struct {
+	message string
+}
+
+func (e *notAcceptable) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return "none of the media types in Accept can be produced"
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// exportFlushEvery is how many contacts are written between flushes to the client, so a large
+// export arrives steadily rather than all at once.
+const exportFlushEvery = 100
+
+// contactWriter writes contacts one at a time in an export format.
+type contactWriter interface {
+	Write(contact models.ContactResponse) error
+}
+
+type exportFormat struct {
+	mediaType string
+	extension string
+	newWriter func(w io.Writer) (contactWriter, error)
+}
+
+// exportFormats are the values of the export endpoint's format parameter.
+var exportFormats = map[string]exportFormat{
+	"csv":   {mediaType: "text/csv; charset=utf-8", extension: "csv", newWriter: newCSVContactWriter},
+	"vcf":   {mediaType: "text/vcard; charset=utf-8", extension: "vcf", newWriter: newVCardContactWriter},
+	"jsonl": {mediaType: "application/x-ndjson", extension: "jsonl", newWriter: newJSONLContactWriter},
+}
+
+// exportMediaTypes returns the media types of the export formats.
+func exportMediaTypes() []string {
+	mediaTypes := make([]string, 0, len(exportFormats))
+	for _, format := range exportFormats {
+		mediaTypes = append(mediaTypes, format.mediaType)
+	}
+	return mediaTypes
+}
+
+// @Summary Export contacts
+// @Description Streams every contact matching the same filters as the list endpoint as a file download.
+// @Description CSV has one row per address, with the columns the import endpoint reads, which groups the rows
+// @Description back into contacts by id; vCard is version 4.0;
+// @Description JSON Lines has one contact per line. The export is streamed, so an error part way through
+// @Description truncates the file rather than returning an error response.
+// @Param format query string true "The file format" Enums(csv, vcf, jsonl)
+// @Param name query string false "Only contacts whose name contains this, ignoring case"
+// @Param city query string false "Only contacts with an address in this city, ignoring case"
+// @Param tag query string false "Only contacts with this tag, ignoring case"
+// @Produce text/csv,text/vcard,application/x-ndjson
+// @Success 200 {file} file
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /contacts/export [get]
+func (ws *webserver) handleExportContacts(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get query params
+	format, err := exportFormatFor(r)
+	if err != nil {
+		return err
+	}
+	filter := contactFilter(r)
+
+	// buffer the writes; the headers are only set once the first buffer is sent, so an error
+	// before then, such as the query failing, is still sent as a normal error response
+	out := &exportStream{w: w, format: format}
+	buf := bufio.NewWriter(out)
+
+	cw, err := format.newWriter(buf)
+	if err != nil {
+		return err
+	}
+
+	// stream the contacts from a cursor
+	n := 0
+	err = db.Contacts.Each(filter, func(contact database.Contact, addresses []database.Address) error {
+		if err := cw.Write(models.MapContactResponse(contact, addresses)); err != nil {
+			return err
+		}
+		if n++; n%exportFlushEvery == 0 {
+			return out.flush(buf)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return out.flush(buf)
+}
+
+// exportFormatFor returns the format an export request asks for, which its Accept header must allow.
+func exportFormatFor(r *http.Request) (exportFormat, error) {
+	format, ok := exportFormats[r.URL.Query().Get("format")]
+	if !ok {
+		return exportFormat{}, &invalidRequest{message: "format must be one of csv, vcf, jsonl"}
+	}
+	if !accepts(r.Header.Get("Accept"), format.mediaType) {
+		return exportFormat{}, &notAcceptable{message: fmt.Sprintf("Accept doesn't allow %s, the media type of the requested format", format.mediaType)}
+	}
+	return format, nil
+}
+
+// exportStream sets the export's headers on the first write.
+type exportStream struct {
+	w       http.ResponseWriter
+	format  exportFormat
+	started bool
+}
+
+func (s *exportStream) Write(b []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("content-type", s.format.mediaType)
+		s.w.Header().Set("content-disposition", fmt.Sprintf(`attachment; filename="contacts.%s"`, s.format.extension))
+	}
+	return s.w.Write(b)
+}
+
+// flush sends everything buffered so far to the client.
+func (s *exportStream) flush(buf *bufio.Writer) error {
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if !s.started {
+		// nothing was buffered; still send the headers for an empty export
+		if _, err := s.Write(nil); err != nil {
+			return err
+		}
+	}
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// csvColumnNames match the fields read by the import endpoint, so an export can be imported. The
+// import groups rows by id back into contacts with several addresses.
+var csvColumnNames = []string{"id", "firstName", "lastName", "company", "title", "notes", "line1", "line2", "city", "stateProvince", "postalCode", "country", "isPrimary"}
+
+type csvContactWriter struct {
+	w *csv.Writer
+}
+
+func newCSVContactWriter(w io.Writer) (contactWriter, error) {
+	cw := csvContactWriter{w: csv.NewWriter(w)}
+	if err := cw.write(csvColumnNames); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// Write writes a row for each of the contact's addresses, or a single row without an address if
+// it has none.
+func (cw csvContactWriter) Write(contact models.ContactResponse) error {
+	fields := []string{fmt.Sprint(contact.ID), contact.FirstName, contact.LastName, models.StringOrEmpty(contact.Company), models.StringOrEmpty(contact.Title), models.StringOrEmpty(contact.Notes)}
+	if len(contact.Addresses) == 0 {
+		return cw.write(append(fields, "", "", "", "", "", "", ""))
+	}
+	for _, address := range contact.Addresses {
+		row := append(fields[:len(fields):len(fields)], address.Line1, models.StringOrEmpty(address.Line2), address.City, address.StateProvince, address.PostalCode, address.Country, strconv.FormatBool(address.IsPrimary))
+		if err := cw.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw csvContactWriter) write(row []string) error {
+	if err := cw.w.Write(row); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type vCardContactWriter struct {
+	w io.Writer
+}
+
+func newVCardContactWriter(w io.Writer) (contactWriter, error) {
+	return vCardContactWriter{w: w}, nil
+}
+
+// Write writes the contact as a vCard 4.0 (RFC 6350) card.
+func (vw vCardContactWriter) Write(contact models.ContactResponse) error {
+	lines := []string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:" + vCardEscape(strings.TrimSpace(contact.FirstName+" "+contact.LastName)),
+		"N:" + vCardEscape(contact.LastName) + ";" + vCardEscape(contact.FirstName) + ";;;",
+	}
+	if contact.Company != nil {
+		lines = append(lines, "ORG:"+vCardEscape(*contact.Company))
+	}
+	if contact.Title != nil {
+		lines = append(lines, "TITLE:"+vCardEscape(*contact.Title))
+	}
+	if contact.Notes != nil {
+		lines = append(lines, "NOTE:"+vCardEscape(*contact.Notes))
+	}
+	for _, address := range contact.Addresses {
+		// ADR is post office box;extended address;street;locality;region;postal code;country
+		lines = append(lines, "ADR:;"+strings.Join([]string{
+			vCardEscape(models.StringOrEmpty(address.Line2)),
+			vCardEscape(address.Line1),
+			vCardEscape(address.City),
+			vCardEscape(address.StateProvince),
+			vCardEscape(address.PostalCode),
+			vCardEscape(countryName(address.Country)),
+		}, ";"))
+	}
+	lines = append(lines,
+		"REV:"+time.Unix(0, contact.UpdatedAt*int64(time.Millisecond)).UTC().Format("20060102T150405Z"),
+		"END:VCARD",
+	)
+
+	for _, line := range lines {
+		if _, err := io.WriteString(vw.w, vCardFold(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var vCardEscaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`)
+
+func vCardEscape(s string) string {
+	return vCardEscaper.Replace(s)
+}
+
+// vCardFold ends line with CRLF, folding it so no line is longer than 75 octets. Lines are only
+// broken between characters, and each continuation starts with a space.
+func vCardFold(line string) string {
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// countryName returns the name of the country with the ISO 3166-1 code, since ADR's country is
+// free text and mail clients show it as it is.
+func countryName(code string) string {
+	if name, ok := postal.CountryName(code); ok {
+		return name
+	}
+	return code
+}
+
+type jsonlContactWriter struct {
+	e *json.Encoder
+}
+
+func newJSONLContactWriter(w io.Writer) (contactWriter, error) {
+	return jsonlContactWriter{e: json.NewEncoder(w)}, nil
+}
+
+// Write writes the contact as a line of JSON, in the same shape as the list endpoint.
+func (jw jsonlContactWriter) Write(contact models.ContactResponse) error {
+	return jw.e.Encode(contact)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/contactimport"
+)
+
+func testExportContacts() []models.ContactResponse {
+	suite, company := "Suite 2", "Analytical Engines Ltd."
+	return []models.ContactResponse{
+		{ID: 1, FirstName: "Ada", LastName: "Lovelace, Countess", Company: &company, Addresses: []models.AddressResponse{
+			{Line1: "12 St. James's Square", Line2: &suite, City: "London", StateProvince: "", PostalCode: "SW1Y 4JH", Country: "GB"},
+			{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701", Country: "US", IsPrimary: true},
+		}},
+		{ID: 2, FirstName: "Alan", LastName: "Turing"},
+	}
+}
+
+func TestCSVContactWriter_OneRowPerAddress(t *testing.T) {
+	// arrange
+	var b bytes.Buffer
+	cw, err := newCSVContactWriter(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	for _, contact := range testExportContacts() {
+		if err := cw.Write(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// assert
+	want := "id,firstName,lastName,company,title,notes,line1,line2,city,stateProvince,postalCode,country,isPrimary\n" +
+		"1,Ada,\"Lovelace, Countess\",Analytical Engines Ltd.,,,12 St. James's Square,Suite 2,London,,SW1Y 4JH,GB,false\n" +
+		"1,Ada,\"Lovelace, Countess\",Analytical Engines Ltd.,,,1 Main St,,Springfield,IL,62701,US,true\n" +
+		"2,Alan,Turing,,,,,,,,,,\n"
+	if b.String() != want {
+		t.Errorf("csv, want:\n%s\ngot:\n%s", want, b.String())
+	}
+}
+
+func TestCSVContactWriter_CanBeImported(t *testing.T) {
+	// arrange
+	var b bytes.Buffer
+	cw, _ := newCSVContactWriter(&b)
+
+	// act
+	for _, contact := range testExportContacts() {
+		if err := cw.Write(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := contactimport.ParseCSV(&b, nil)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records, want: 2 got: %d", len(records))
+	}
+	if len(records[0].Addresses) != 2 || len(records[1].Addresses) != 0 {
+		t.Fatalf("addresses, want: 2 and 0 got: %d and %d", len(records[0].Addresses), len(records[1].Addresses))
+	}
+	if company := records[0].Contact.Company; company == nil || *company != "Analytical Engines Ltd." {
+		t.Errorf("company, want: Analytical Engines Ltd. got: %v", company)
+	}
+	if address := records[0].Addresses[1]; address.Line1 != "1 Main St" || address.StateProvince != "IL" || address.Country != "US" {
+		t.Errorf("address, got: %+v", address)
+	}
+	// the primary address isn't the first, so importing the flag keeps it primary
+	if records[0].Addresses[0].IsPrimary || !records[0].Addresses[1].IsPrimary {
+		t.Errorf("isPrimary, want: false and true got: %v and %v", records[0].Addresses[0].IsPrimary, records[0].Addresses[1].IsPrimary)
+	}
+}
+
+func TestVCardContactWriter_CanBeImported(t *testing.T) {
+	// arrange
+	var b bytes.Buffer
+	cw, _ := newVCardContactWriter(&b)
+
+	// act
+	for _, contact := range testExportContacts() {
+		if err := cw.Write(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := contactimport.ParseVCard(&b)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records, want: 2 got: %d", len(records))
+	}
+	if records[0].Contact.LastName != "Lovelace, Countess" || records[0].Contact.FirstName != "Ada" {
+		t.Errorf("name, want: Ada Lovelace, Countess got: %s %s", records[0].Contact.FirstName, records[0].Contact.LastName)
+	}
+	if company := records[0].Contact.Company; company == nil || *company != "Analytical Engines Ltd." {
+		t.Errorf("company, want: Analytical Engines Ltd. got: %v", company)
+	}
+	if len(records[0].Addresses) != 2 || len(records[1].Addresses) != 0 {
+		t.Fatalf("addresses, want: 2 and 0 got: %d and %d", len(records[0].Addresses), len(records[1].Addresses))
+	}
+	address := records[0].Addresses[0]
+	if address.Line1 != "12 St. James's Square" || address.Line2 == nil || *address.Line2 != "Suite 2" || address.PostalCode != "SW1Y 4JH" || address.Country != "GB" {
+		t.Errorf("address, got: %+v", address)
+	}
+}
+
+func TestVCardFold(t *testing.T) {
+	// arrange
+	line := "NOTE:" + strings.Repeat("é", 40)
+
+	// act
+	folded := vCardFold(line)
+
+	// assert
+	for _, l := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line length, want: <= 75 got: %d", len(l))
+		}
+	}
+	if unfolded := strings.Replace(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "", -1); unfolded != line {
+		t.Errorf("unfolded, want: %q got: %q", line, unfolded)
+	}
+}
+
+func TestJSONLContactWriter_OneContactPerLine(t *testing.T) {
+	// arrange
+	var b bytes.Buffer
+	cw, _ := newJSONLContactWriter(&b)
+
+	// act
+	for _, contact := range testExportContacts() {
+		if err := cw.Write(contact); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// assert
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines, want: 2 got: %d", len(lines))
+	}
+	if !strings.HasPrefix(lines[1], `{"id":2,"firstName":"Alan"`) {
+		t.Errorf("line 2, got: %s", lines[1])
+	}
+}
+
+func TestExportStream_SetsHeadersOnFirstWrite(t *testing.T) {
+	// arrange
+	rec := httptest.NewRecorder()
+	out := &exportStream{w: rec, format: exportFormats["vcf"]}
+	buf := bufio.NewWriter(out)
+	_, _ = buf.WriteString("BEGIN:VCARD\r\n")
+
+	// act
+	before := rec.Header().Get("content-disposition")
+	err := out.flush(buf)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != "" {
+		t.Errorf("content-disposition before flush, want: none got: %q", before)
+	}
+	if got := rec.Header().Get("content-disposition"); got != `attachment; filename="contacts.vcf"` {
+		t.Errorf("content-disposition, want: %q got: %q", `attachment; filename="contacts.vcf"`, got)
+	}
+	if got := rec.Header().Get("content-type"); got != "text/vcard; charset=utf-8" {
+		t.Errorf("content-type, want: text/vcard; charset=utf-8 got: %q", got)
+	}
+	if !rec.Flushed {
+		t.Error("flushed, want: true got: false")
+	}
+}
+
+// serveExport serves an export request the way the export route does, writing the formats without
+// a database.
+func serveExport(query, accept string) *httptest.ResponseRecorder {
+	ws := newTestWebserver()
+	h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		format, err := exportFormatFor(r)
+		if err != nil {
+			return err
+		}
+		out := &exportStream{w: w, format: format}
+		buf := bufio.NewWriter(out)
+		if _, err := format.newWriter(buf); err != nil {
+			return err
+		}
+		return out.flush(buf)
+	}, exportMediaTypes()...)
+
+	req := httptest.NewRequest("GET", "/api/v1/contacts/export?"+query, nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExport_EachFormatWithItsAcceptHeader(t *testing.T) {
+	tests := []struct {
+		format string
+		accept string
+	}{
+		{"csv", "text/csv"},
+		{"vcf", "text/vcard"},
+		{"jsonl", "application/x-ndjson"},
+		{"vcf", "text/*"},
+		{"jsonl", "application/json, application/x-ndjson;q=0.5"},
+	}
+
+	for _, tt := range tests {
+		// act
+		rec := serveExport("format="+tt.format, tt.accept)
+
+		// assert
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s with Accept %q: status, want: 200 got: %d %s", tt.format, tt.accept, rec.Code, rec.Body.String())
+			continue
+		}
+		if got := rec.Header().Get("content-type"); got != exportFormats[tt.format].mediaType {
+			t.Errorf("%s with Accept %q: content-type, want: %q got: %q", tt.format, tt.accept, exportFormats[tt.format].mediaType, got)
+		}
+	}
+}
+
+func TestExport_RefusesFormatAcceptDoesNotAllow(t *testing.T) {
+	// act
+	rec := serveExport("format=csv", "text/vcard")
+
+	// assert
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("status, want: 406 got: %d", rec.Code)
+	}
+	if got := rec.Header().Get("content-type"); got != "application/json" {
+		t.Errorf("content-type, want: application/json got: %q", got)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// format encodes responses and decodes request bodies in one media type.
+type format struct {
+	mediaType string
+	aliases   []string // other media types clients use for the same format
+
+	// marshal encodes a response. It returns errUnsupportedValue when the format can't represent
+	// the value, e.g. CSV and a single object, so the next acceptable format can be tried.
+	marshal func(v interface{}) ([]byte, error)
+
+	// decode decodes a request body, returning errors that describe what was wrong with it.
+	decode func(r io.Reader, v interface{}) error
+}
+
+var errUnsupportedValue = errors.New("value can't be represented in this format")
+
+// formats are in order of preference; the first is used when the client doesn't say.
+var formats = []*format{
+	{mediaType: "application/json", marshal: json.Marshal, decode: decodeJSONBody},
+	{mediaType: "application/xml", aliases: []string{"text/xml"}, marshal: marshalXML, decode: decodeXMLBody},
+	{mediaType: "text/csv", marshal: marshalCSV, decode: decodeCSVBody},
+	{mediaType: "application/msgpack", aliases: []string{"application/x-msgpack", "application/vnd.msgpack"}, marshal: marshalMsgpack, decode: decodeMsgpackBody},
+}
+
+func (f *format) matches(mediaType string) bool {
+	if strings.EqualFold(f.mediaType, mediaType) {
+		return true
+	}
+	for _, alias := range f.aliases {
+		if strings.EqualFold(alias, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFor returns the format for a Content-Type, or nil if there isn't one.
+func formatFor(mediaType string) *format {
+	for _, f := range formats {
+		if f.matches(mediaType) {
+			return f
+		}
+	}
+	return nil
+}
+
+// acceptableFormats returns the formats the Accept header allows, most preferred first. Each
+// format gets the quality of the most specific media range matching it; ties go to the range
+// listed first, then to our order. No header accepts everything.
+func acceptableFormats(accept string) []*format {
+	return negotiate(accept, formats)
+}
+
+// accepts reports whether the Accept header allows mediaType, ignoring its parameters.
+func accepts(accept string, mediaType string) bool {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		mediaType = parsed
+	}
+	return len(negotiate(accept, []*format{{mediaType: mediaType}})) > 0
+}
+
+// negotiate returns the formats the Accept header allows, most preferred first, see acceptableFormats.
+func negotiate(accept string, offered []*format) []*format {
+	if strings.TrimSpace(accept) == "" {
+		return offered
+	}
+
+	type candidate struct {
+		format      *format
+		quality     float64
+		specificity int // 3 for type/subtype, 2 for type/*, 1 for */*
+		position    int
+	}
+
+	var candidates []candidate
+	for order, f := range offered {
+		best := candidate{format: f, position: order}
+		for position, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			specificity := 0
+			switch {
+			case f.matches(mediaType):
+				specificity = 3
+			case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(f.mediaType, strings.TrimSuffix(mediaType, "*")):
+				specificity = 2
+			case mediaType == "*/*":
+				specificity = 1
+			}
+			if specificity <= best.specificity {
+				continue
+			}
+
+			quality := 1.0
+			if q, ok := params["q"]; ok {
+				if quality, err = strconv.ParseFloat(q, 64); err != nil {
+					quality = 0
+				}
+			}
+			best = candidate{format: f, quality: quality, specificity: specificity, position: position*len(offered) + order}
+		}
+		if best.specificity > 0 && best.quality > 0 {
+			candidates = append(candidates, best)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].quality != candidates[j].quality {
+			return candidates[i].quality > candidates[j].quality
+		}
+		return candidates[i].position < candidates[j].position
+	})
+
+	acceptable := make([]*format, len(candidates))
+	for i, c := range candidates {
+		acceptable[i] = c.format
+	}
+	return acceptable
+}
+
+// marshalResponse encodes value in the first format the client accepts that can represent it.
+// Outside of handler there's no negotiation and JSON is used.
+func marshalResponse(w http.ResponseWriter, value interface{}) (*format, []byte, error) {
+	acceptable := formats[:1]
+	if rw, ok := w.(*responseWriter); ok {
+		acceptable = rw.formats
+	}
+
+	for _, f := range acceptable {
+		b, err := f.marshal(value)
+		if err == errUnsupportedValue {
+			continue
+		}
+		return f, b, err
+	}
+	return nil, nil, &notAcceptable{}
+}
+
+// unsupportedContentType lists the Content-Types decode accepts.
+func unsupportedContentType() error {
+	mediaTypes := make([]string, len(formats))
+	for i, f := range formats {
+		mediaTypes[i] = f.mediaType
+	}
+	return &unsupportedMediaType{message: "Content-Type must be one of " + strings.Join(mediaTypes, ", ")}
+}
+
+// marshalXML encodes a struct as an element named after its type, e.g. ContactResponse as
+// <contact>, and a slice as a list of them, e.g. <contacts><contact>...</contact></contacts>.
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Slice {
+		name := xmlElementName(value.Type().Elem())
+		list := xml.StartElement{Name: xml.Name{Local: pluralize(name)}}
+		if err := enc.EncodeToken(list); err != nil {
+			return nil, err
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := enc.EncodeElement(value.Index(i).Interface(), xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+				return nil, err
+			}
+		}
+		if err := enc.EncodeToken(list.End()); err != nil {
+			return nil, err
+		}
+	} else if err := enc.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: xmlElementName(value.Type())}}); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xmlElementName names the element for a response type: ContactResponse becomes contact.
+func xmlElementName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := strings.TrimSuffix(t.Name(), "Response")
+	if name == "" {
+		return "response"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func pluralize(name string) string {
+	if strings.HasSuffix(name, "s") {
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// decodeXMLBody decodes a single XML element. The root element's name isn't checked, and like
+// encoding/xml generally, unknown child elements are ignored.
+func decodeXMLBody(r io.Reader, v interface{}) error {
+	dec := xml.NewDecoder(r)
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			return &requestTooLarge{limit: tooLarge.Limit}
+		case err == io.EOF:
+			return &invalidRequest{message: "body must not be empty"}
+		default:
+			return &invalidRequest{message: "body contains badly-formed XML: " + strings.TrimPrefix(err.Error(), "XML syntax error on ")}
+		}
+	}
+
+	// only whitespace may follow the element
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return decodeError(err)
+		}
+		if data, ok := token.(xml.CharData); !ok || len(bytes.TrimSpace(data)) > 0 {
+			return &invalidRequest{message: "body must only contain a single XML element"}
+		}
+	}
+}
+
+func marshalMsgpack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	// use the json tags so field names match the JSON API
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeMsgpackBody(r io.Reader, v interface{}) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return decodeError(err)
+	}
+	if len(b) == 0 {
+		return &invalidRequest{message: "body must not be empty"}
+	}
+
+	body := bytes.NewReader(b)
+	dec := msgpack.NewDecoder(body)
+	dec.SetCustomStructTag("json")
+	dec.DisallowUnknownFields(true)
+	if err := dec.Decode(v); err != nil {
+		return &invalidRequest{message: "body contains invalid MessagePack: " + strings.TrimPrefix(err.Error(), "msgpack: ")}
+	}
+	if body.Len() > 0 {
+		return &invalidRequest{message: "body must only contain a single MessagePack value"}
+	}
+	return nil
+}
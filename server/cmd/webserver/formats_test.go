@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func mediaTypes(formats []*format) string {
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = f.mediaType
+	}
+	return strings.Join(names, ",")
+}
+
+func TestAcceptableFormats(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: "application/json,application/xml,text/csv,application/msgpack"},
+		{accept: "*/*", want: "application/json,application/xml,text/csv,application/msgpack"},
+		{accept: "text/csv", want: "text/csv"},
+		{accept: "application/xml, application/json", want: "application/xml,application/json"},
+		{accept: "application/json;q=0.5, text/xml", want: "application/xml,application/json"},
+		{accept: "application/*;q=0.5, application/msgpack", want: "application/msgpack,application/json,application/xml"},
+		{accept: "*/*;q=0.1, application/json;q=0", want: "application/xml,text/csv,application/msgpack"},
+		{accept: "text/html", want: ""},
+	}
+
+	for _, tt := range tests {
+		// act
+		got := mediaTypes(acceptableFormats(tt.accept))
+
+		// assert
+		if got != tt.want {
+			t.Errorf("acceptableFormats(%q), want: %q got: %q", tt.accept, tt.want, got)
+		}
+	}
+}
+
+func serveWithAccept(accept string, value interface{}) *httptest.ResponseRecorder {
+	ws := newTestWebserver()
+	h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		return Ok(w, value)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+var testAddresses = []models.AddressResponse{
+	{ID: 1, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701", Country: "US",
+		FormattedAddress: "1 Main St\nSpringfield, IL 62701\nUNITED STATES", CreatedAt: 10, UpdatedAt: 20},
+}
+
+func TestOk_CSVForLists(t *testing.T) {
+	// act
+	rec := serveWithAccept("text/csv", testAddresses)
+
+	// assert
+	if got := rec.Header().Get("content-type"); got != "text/csv" {
+		t.Errorf("content-type, want: text/csv got: %q", got)
+	}
+	want := "id,line1,line2,city,stateProvince,postalCode,country,formattedAddress,lat,lng,isPrimary,createdAt,updatedAt\n" +
+		"1,1 Main St,,Springfield,IL,62701,US,\"1 Main St\nSpringfield, IL 62701\nUNITED STATES\",,,false,10,20\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body, want: %q got: %q", want, got)
+	}
+}
+
+func TestOk_CSVOnlyForSingleObjectIsNotAcceptable(t *testing.T) {
+	// act
+	rec := serveWithAccept("text/csv", testAddresses[0])
+
+	// assert
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("status, want: %d got: %d", http.StatusNotAcceptable, rec.Code)
+	}
+	if got := rec.Header().Get("content-type"); got != "application/json" {
+		t.Errorf("content-type, want: application/json got: %q", got)
+	}
+}
+
+func TestOk_CSVFallsBackForSingleObject(t *testing.T) {
+	// act
+	rec := serveWithAccept("text/csv, application/json;q=0.5", testAddresses[0])
+
+	// assert
+	if rec.Code != http.StatusOK {
+		t.Errorf("status, want: %d got: %d", http.StatusOK, rec.Code)
+	}
+	if got := rec.Header().Get("content-type"); got != "application/json" {
+		t.Errorf("content-type, want: application/json got: %q", got)
+	}
+}
+
+func TestOk_XML(t *testing.T) {
+	// act
+	rec := serveWithAccept("application/xml", []models.ContactResponse{{ID: 1, FirstName: "John", Addresses: testAddresses}})
+
+	// assert
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<contacts><contact><id>1</id><firstName>John</firstName>",
+		"<addresses><address><id>1</id><line1>1 Main St</line1>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body, want to contain: %q got: %q", want, body)
+		}
+	}
+}
+
+func TestOk_MessagePackUsesJSONNames(t *testing.T) {
+	// act
+	rec := serveWithAccept("application/msgpack", models.PingResponse{Message: "pong"})
+
+	// assert
+	var got map[string]string
+	if err := msgpack.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["msg"] != "pong" {
+		t.Errorf("msg, want: %q got: %q", "pong", got["msg"])
+	}
+}
+
+func TestHandler_NotAcceptableSkipsHandler(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	called := false
+	h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		called = true
+		return Ok(w, struct{}{})
+	})
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("status, want: %d got: %d", http.StatusNotAcceptable, rec.Code)
+	}
+	if called {
+		t.Errorf("handler called, want: false got: true")
+	}
+}
+
+func TestDecode_OtherFormats(t *testing.T) {
+	packed, _ := marshalMsgpack(models.AddressRequest{Line1: "1 Main St", City: "Springfield"})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		wantStatus  int
+		wantLine1   string
+		wantLine2   string
+	}{
+		{name: "xml", contentType: "application/xml", body: []byte(`<address><line1>1 Main St</line1><line2>Apt 2</line2></address>`), wantStatus: http.StatusOK, wantLine1: "1 Main St", wantLine2: "Apt 2"},
+		{name: "xml trailing", contentType: "text/xml", body: []byte(`<address></address><address></address>`), wantStatus: http.StatusBadRequest},
+		{name: "csv", contentType: "text/csv", body: []byte("line1,line2,city\n1 Main St,,Springfield\n"), wantStatus: http.StatusOK, wantLine1: "1 Main St"},
+		{name: "csv unknown column", contentType: "text/csv", body: []byte("line3\nx\n"), wantStatus: http.StatusBadRequest},
+		{name: "csv two records", contentType: "text/csv", body: []byte("line1\na\nb\n"), wantStatus: http.StatusBadRequest},
+		{name: "msgpack", contentType: "application/msgpack", body: packed, wantStatus: http.StatusOK, wantLine1: "1 Main St"},
+		{name: "msgpack garbage", contentType: "application/x-msgpack", body: []byte{0xc1}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		ws := newTestWebserver()
+		var request models.AddressRequest
+		h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+			if err := ws.decode(w, r, &request); err != nil {
+				return err
+			}
+			return Ok(w, struct{}{})
+		})
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		rec := httptest.NewRecorder()
+
+		// act
+		h.ServeHTTP(rec, req)
+
+		// assert
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status, want: %d got: %d (%s)", tt.name, tt.wantStatus, rec.Code, rec.Body.String())
+			continue
+		}
+		if request.Line1 != tt.wantLine1 {
+			t.Errorf("%s: Line1, want: %q got: %q", tt.name, tt.wantLine1, request.Line1)
+		}
+		line2 := ""
+		if request.Line2 != nil {
+			line2 = *request.Line2
+		}
+		if line2 != tt.wantLine2 {
+			t.Errorf("%s: Line2, want: %q got: %q", tt.name, tt.wantLine2, line2)
+		}
+	}
+}
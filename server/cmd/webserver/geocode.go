@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/geocode"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+// geocoders selectable with --geocoder
+const (
+	geocoderNone    = "none"
+	geocoderOffline = "offline"
+)
+
+const (
+	// defaultNearRadius is the radius of /contacts/near, in kilometres, when none is given.
+	defaultNearRadius = 10
+	// maxNearRadius keeps /contacts/near from reading most of the addresses table.
+	maxNearRadius = 500
+)
+
+// newGeocoder returns the geocoder named by --geocoder, or nil for none. The offline geocoder reads
+// its postal code table from dataFile, or uses the sample compiled into the binary if that's empty.
+func newGeocoder(name, dataFile string) (geocode.Geocoder, error) {
+	switch name {
+	case geocoderNone:
+		return nil, nil
+	case geocoderOffline:
+		offline, err := loadOfflineGeocoder(dataFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("loaded postal code centroids", zap.Int("postal_codes", offline.Len()))
+		return offline, nil
+	}
+	return nil, fmt.Errorf("unknown geocoder %q", name)
+}
+
+func loadOfflineGeocoder(dataFile string) (*geocode.Offline, error) {
+	if dataFile == "" {
+		return geocode.Bundled()
+	}
+
+	f, err := os.Open(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	offline, err := geocode.NewOffline(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", dataFile, err)
+	}
+	return offline, nil
+}
+
+// @Summary Find contacts near a point
+// @Description Returns the contacts with an address within radius kilometres of lat,lng, nearest first, each
+// @Description with its nearest address and the distance to it. Addresses are geocoded in the background
+// @Description after they're saved, so a new address may take a moment to be found.
+// @Param lat query number true "Latitude in degrees"
+// @Param lng query number true "Longitude in degrees"
+// @Param radius query number false "Radius in kilometres, up to 500" default(10)
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.NearbyContactResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /contacts/near [get]
+func (ws *webserver) handleGetContactsNear(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get query params
+	lat, lng, radius, err := nearQuery(r)
+	if err != nil {
+		return err
+	}
+
+	nearby, err := db.Contacts.Near(lat, lng, radius)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, models.MapNearbyContactResponses(nearby))
+}
+
+// nearQuery reads the point and radius of a /contacts/near request. The database checks the point
+// is on the globe.
+func nearQuery(r *http.Request) (lat, lng, radius float64, err error) {
+	query := r.URL.Query()
+
+	if lat, err = queryFloat(query.Get("lat"), "lat"); err != nil {
+		return 0, 0, 0, err
+	}
+	if lng, err = queryFloat(query.Get("lng"), "lng"); err != nil {
+		return 0, 0, 0, err
+	}
+
+	radius = defaultNearRadius
+	if value := query.Get("radius"); value != "" {
+		if radius, err = queryFloat(value, "radius"); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	if radius <= 0 || radius > maxNearRadius {
+		return 0, 0, 0, &invalidRequest{message: fmt.Sprintf("radius must be greater than 0 and at most %d", maxNearRadius)}
+	}
+
+	return lat, lng, radius, nil
+}
+
+func queryFloat(value, name string) (float64, error) {
+	if value == "" {
+		return 0, &invalidRequest{message: name + " is required"}
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, &invalidRequest{message: fmt.Sprintf("%s %q must be a number", name, value)}
+	}
+	return f, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNearQuery(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantRadius float64
+		wantErr    bool
+	}{
+		{query: "lat=39.8&lng=-89.6", wantRadius: defaultNearRadius},
+		{query: "lat=39.8&lng=-89.6&radius=2.5", wantRadius: 2.5},
+		{query: "lng=-89.6", wantErr: true},
+		{query: "lat=north&lng=-89.6", wantErr: true},
+		{query: "lat=NaN&lng=-89.6", wantErr: true},
+		{query: "lat=39.8&lng=-89.6&radius=0", wantErr: true},
+		{query: "lat=39.8&lng=-89.6&radius=501", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		r := httptest.NewRequest("GET", "/api/v1/contacts/near?"+tt.query, nil)
+
+		// act
+		lat, lng, radius, err := nearQuery(r)
+
+		// assert
+		if tt.wantErr {
+			if !isInvalidRequest(err) {
+				t.Errorf("%s: error, want: invalid request got: %v", tt.query, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error, want: nil got: %v", tt.query, err)
+		}
+		if lat != 39.8 || lng != -89.6 || radius != tt.wantRadius {
+			t.Errorf("%s: want: 39.8 -89.6 %v got: %v %v %v", tt.query, tt.wantRadius, lat, lng, radius)
+		}
+	}
+}
+
+func TestNewGeocoder(t *testing.T) {
+	// arrange
+	bad := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(bad, []byte("US\t62701\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	none, noneErr := newGeocoder(geocoderNone, "")
+	bundled, bundledErr := newGeocoder(geocoderOffline, "")
+	_, badErr := newGeocoder(geocoderOffline, bad)
+
+	// assert
+	if none != nil || noneErr != nil {
+		t.Errorf("none, want: nil, nil got: %v, %v", none, noneErr)
+	}
+	if bundled == nil || bundledErr != nil {
+		t.Errorf("offline, want: a geocoder got: %v, %v", bundled, bundledErr)
+	}
+	if badErr == nil {
+		t.Errorf("offline with a malformed file, want: error got: nil")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/health"
+)
+
+// healthCheckTimeout bounds how long a single health check may take before it's reported as failing.
+const healthCheckTimeout = 2 * time.Second
+
+// registerHealthChecks sets up the checks behind /healthz and /readyz. Liveness only proves the
+// process is serving requests, so it has no checks by default; add one only for a condition a
+// restart would fix. Readiness fails whenever the instance shouldn't receive traffic.
+func (ws *webserver) registerHealthChecks() {
+	ws.liveness = health.NewRegistry(healthCheckTimeout)
+
+	ws.readiness = health.NewRegistry(healthCheckTimeout)
+	ws.readiness.RegisterFunc("database", ws.db.Ping)
+	ws.readiness.RegisterFunc("migrations", migrationsCheck(ws.db.PendingMigrations))
+	ws.readiness.RegisterFunc("shutdown", func(_ context.Context) error {
+		if ws.isShuttingDown() {
+			return errors.New("server is shutting down")
+		}
+		return nil
+	})
+}
+
+// migrationsCheck fails while pending reports migrations to apply. Once none are pending it passes
+// without querying again, since the schema only changes when an instance starts. A failure isn't
+// cached: another instance, or a separate deployment step, may still apply them.
+func migrationsCheck(pending func(ctx context.Context) ([]int, error)) func(ctx context.Context) error {
+	var upToDate int32 // accessed atomically, 1 once no migrations were pending
+	return func(ctx context.Context) error {
+		if atomic.LoadInt32(&upToDate) == 1 {
+			return nil
+		}
+		versions, err := pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(versions) > 0 {
+			return fmt.Errorf("%d pending migration(s): %v", len(versions), versions)
+		}
+		atomic.StoreInt32(&upToDate, 1)
+		return nil
+	}
+}
+
+func (ws *webserver) isShuttingDown() bool {
+	return atomic.LoadInt32(&ws.shuttingDown) == 1
+}
+
+// handleHealthz reports whether the process is alive. It is intentionally not under /api/v1
+// so orchestrator probes don't depend on the API version.
+func (ws *webserver) handleHealthz(w http.ResponseWriter, r *http.Request) error {
+	return writeHealthReport(w, r, ws.liveness)
+}
+
+// handleReadyz reports whether the instance can serve traffic: the database is reachable, the
+// schema is up to date and the server isn't shutting down.
+func (ws *webserver) handleReadyz(w http.ResponseWriter, r *http.Request) error {
+	return writeHealthReport(w, r, ws.readiness)
+}
+
+func writeHealthReport(w http.ResponseWriter, r *http.Request, registry *health.Registry) error {
+	report := registry.Run(r.Context())
+
+	status := http.StatusOK
+	if !report.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	return Respond(w, status, models.MapHealthResponse(report))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMigrationsCheck_CachesOnlyUpToDate(t *testing.T) {
+	// arrange
+	calls := 0
+	results := [][]int{{5}, {}}
+	check := migrationsCheck(func(_ context.Context) ([]int, error) {
+		pending := results[calls]
+		calls++
+		return pending, nil
+	})
+
+	// act
+	pendingErr := check(context.Background())
+	upToDateErr := check(context.Background())
+	cachedErr := check(context.Background())
+
+	// assert
+	if pendingErr == nil {
+		t.Error("pending migrations, want: error got: nil")
+	}
+	if upToDateErr != nil {
+		t.Errorf("no pending migrations, want: nil got: %v", upToDateErr)
+	}
+	if cachedErr != nil {
+		t.Errorf("cached, want: nil got: %v", cachedErr)
+	}
+	if calls != 2 {
+		t.Errorf("calls, want: 2 got: %d", calls)
+	}
+}
+
+func TestMigrationsCheck_PassesContext(t *testing.T) {
+	// arrange
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "check")
+	var got context.Context
+	check := migrationsCheck(func(ctx context.Context) ([]int, error) {
+		got = ctx
+		return nil, nil
+	})
+
+	// act
+	if err := check(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if got == nil || got.Value(key{}) != "check" {
+		t.Error("the check's context wasn't passed to pending")
+	}
+}
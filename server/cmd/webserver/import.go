@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/contactimport"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+// import modes
+const (
+	importModeAtomic  = "atomic"  // import every record in one transaction, or nothing if any is invalid
+	importModePartial = "partial" // import each valid record in its own transaction and report the rest
+)
+
+// statuses of a record in an import report
+const (
+	importStatusCreated = "created"
+	importStatusSkipped = "skipped"
+	importStatusFailed  = "failed"
+)
+
+// @Summary Import contacts from CSV or vCard
+// @Description Imports a CSV file with a header row, or a vCard 3.0/4.0 file. CSV columns are matched to
+// @Description firstName, lastName, company, title, notes, line1, line2, city, stateProvince, postalCode,
+// @Description country and isPrimary by name, or mapped with map.<field>=<column> query parameters, e.g.
+// @Description map.firstName=Given%20Name. CSV rows with the same id column are one contact with several
+// @Description addresses, as the export endpoint writes them. A vCard's N or FN, ORG, TITLE, NOTE and ADR
+// @Description properties are read.
+// @Description Row is the CSV line a record starts on, or the position of the card in a vCard file.
+// @Param mode query string false "atomic imports every record or none; partial imports the valid ones" Enums(atomic, partial)
+// @Accept text/csv,text/vcard
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.ImportResponse
+// @Failure 400 {object} models.ImportResponse
+// @Failure 404 {object} models.ImportResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 500 {object} models.ImportResponse
+// @Router /contacts/import [post]
+func (ws *webserver) handleImportContacts(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get query params
+	query := r.URL.Query()
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = importModeAtomic
+	}
+	if mode != importModeAtomic && mode != importModePartial {
+		return &invalidRequest{message: fmt.Sprintf("mode must be %q or %q", importModeAtomic, importModePartial)}
+	}
+
+	mapping := make(map[string]string)
+	for key, values := range query {
+		if field := strings.TrimPrefix(key, "map."); field != key {
+			mapping[field] = values[0]
+		}
+	}
+
+	// read the file
+	records, err := ws.readImport(w, r, mapping)
+	if err != nil {
+		return err
+	}
+
+	// import the records
+	response, err := importRecords(db, records, mode)
+	if err != nil {
+		// the report says which row failed; the status says whether it was the row or the database
+		if respondErr := Respond(w, httpStatus(err), response); respondErr != nil {
+			return respondErr
+		}
+		return err
+	}
+
+	if !response.Committed {
+		return Respond(w, http.StatusBadRequest, response)
+	}
+	return Ok(w, response)
+}
+
+// readImport parses the body according to its Content-Type. Import files are typically much
+// larger than other request bodies, so they have their own size limit.
+func (ws *webserver) readImport(w http.ResponseWriter, r *http.Request, mapping map[string]string) ([]contactimport.Record, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, &unsupportedMediaType{message: "Content-Type must be text/csv or text/vcard"}
+	}
+
+	if ws.maxImportBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, ws.maxImportBytes)
+	}
+
+	var records []contactimport.Record
+	switch mediaType {
+	case "text/csv":
+		records, err = contactimport.ParseCSV(r.Body, mapping)
+	case "text/vcard", "text/x-vcard":
+		if len(mapping) > 0 {
+			return nil, &invalidRequest{message: "column mappings only apply to CSV"}
+		}
+		records, err = contactimport.ParseVCard(r.Body)
+	default:
+		return nil, &unsupportedMediaType{message: "Content-Type must be text/csv or text/vcard"}
+	}
+
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, &requestTooLarge{limit: tooLarge.Limit}
+		}
+		return nil, &invalidRequest{message: err.Error()}
+	}
+	return records, nil
+}
+
+// importRecords validates every record, skipping empty ones and repeats of an earlier record,
+// then creates the valid ones according to mode. If an atomic import fails part way through, the
+// error is returned along with the report, which says which row failed.
+func importRecords(db database.DB, records []contactimport.Record, mode string) (_ models.ImportResponse, importErr error) {
+	response := models.ImportResponse{
+		Mode: mode,
+		Rows: make([]models.ImportRowResponse, len(records)),
+	}
+
+	var valid []int
+	seen := make(map[string]int)
+	for i, record := range records {
+		row := &response.Rows[i]
+		row.Row = record.Row
+
+		if record.IsEmpty() {
+			row.Status, row.Message = importStatusSkipped, "empty record"
+			continue
+		}
+		if err := contactimport.Validate(record); err != nil {
+			row.Status, row.Message = importStatusFailed, err.Error()
+			continue
+		}
+		if first, ok := seen[record.Key()]; ok {
+			row.Status, row.Message = importStatusSkipped, fmt.Sprintf("duplicate of row %d", first)
+			continue
+		}
+		seen[record.Key()] = record.Row
+		valid = append(valid, i)
+	}
+
+	switch mode {
+	case importModeAtomic:
+		if failed := countStatus(response.Rows, importStatusFailed); failed > 0 {
+			for _, i := range valid {
+				response.Rows[i].Status, response.Rows[i].Message = importStatusSkipped, "not imported because other records failed"
+			}
+			response.Error = fmt.Sprintf("%d records failed validation, nothing was imported", failed)
+			break
+		}
+
+		failedRow := -1
+		err := db.Transaction(func(tx database.DB) error {
+			for _, i := range valid {
+				contactID, err := createRecord(tx, records[i])
+				if err != nil {
+					failedRow = i
+					return fmt.Errorf("row %d: %w", records[i].Row, err)
+				}
+				response.Rows[i].Status, response.Rows[i].ContactID = importStatusCreated, contactID
+			}
+			return nil
+		})
+		if err != nil {
+			// the transaction rolled back, so the rows it created weren't imported after all
+			message := "not imported because the transaction failed"
+			if failedRow >= 0 {
+				message = fmt.Sprintf("not imported because row %d failed", records[failedRow].Row)
+			}
+			for _, i := range valid {
+				response.Rows[i].Status, response.Rows[i].Message, response.Rows[i].ContactID = importStatusSkipped, message, 0
+			}
+			if failedRow >= 0 {
+				response.Rows[failedRow].Status, response.Rows[failedRow].Message = importStatusFailed, errors.Unwrap(err).Error()
+			}
+			response.Error = fmt.Sprintf("%v, nothing was imported", err)
+			importErr = err
+			break
+		}
+		response.Committed = true
+
+	case importModePartial:
+		for _, i := range valid {
+			var contactID int
+			err := db.Transaction(func(tx database.DB) (err error) {
+				contactID, err = createRecord(tx, records[i])
+				return err
+			})
+			if err != nil {
+				response.Rows[i].Status, response.Rows[i].Message = importStatusFailed, err.Error()
+				continue
+			}
+			response.Rows[i].Status, response.Rows[i].ContactID = importStatusCreated, contactID
+		}
+		response.Committed = true
+	}
+
+	response.Created = countStatus(response.Rows, importStatusCreated)
+	response.Skipped = countStatus(response.Rows, importStatusSkipped)
+	response.Failed = countStatus(response.Rows, importStatusFailed)
+	return response, importErr
+}
+
+// createRecord creates the record's contact and its addresses, returning the contact's ID.
+func createRecord(db database.DB, record contactimport.Record) (int, error) {
+	contact, _, err := db.Contacts.CreateWithAddresses(record.Contact, record.Addresses)
+	return contact.ID, err
+}
+
+func countStatus(rows []models.ImportRowResponse, status string) int {
+	n := 0
+	for _, row := range rows {
+		if row.Status == status {
+			n++
+		}
+	}
+	return n
+}
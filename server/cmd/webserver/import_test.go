@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/contactimport"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+func TestReadImport_ContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		wantStatus  int
+		wantRecords int
+	}{
+		{name: "csv", contentType: "text/csv; charset=utf-8", wantRecords: 1},
+		{name: "vcard", contentType: "text/vcard", wantStatus: http.StatusBadRequest},
+		{name: "json", contentType: "application/json", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "missing", wantStatus: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		ws := newTestWebserver()
+		req := httptest.NewRequest("POST", "/api/v1/contacts/import", strings.NewReader("First Name,Last Name\nAda,Lovelace\n"))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+
+		// act
+		records, err := ws.readImport(httptest.NewRecorder(), req, map[string]string{"firstName": "First Name", "lastName": "Last Name"})
+
+		// assert
+		if tt.wantStatus != 0 {
+			if status := httpStatus(err); status != tt.wantStatus {
+				t.Errorf("%s: status, want: %d got: %d (%v)", tt.name, tt.wantStatus, status, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(records) != tt.wantRecords {
+			t.Errorf("%s: records, want: %d got: %d", tt.name, tt.wantRecords, len(records))
+		}
+	}
+}
+
+func TestReadImport_TooLarge(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.maxImportBytes = 16
+	req := httptest.NewRequest("POST", "/api/v1/contacts/import", strings.NewReader("firstName\n"+strings.Repeat("Ada\n", 10)))
+	req.Header.Set("Content-Type", "text/csv")
+
+	// act
+	_, err := ws.readImport(httptest.NewRecorder(), req, nil)
+
+	// assert
+	if status := httpStatus(err); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("status, want: %d got: %d (%v)", http.StatusRequestEntityTooLarge, status, err)
+	}
+}
+
+func TestImportRecords_AtomicReportsEveryRow(t *testing.T) {
+	// arrange
+	records, err := contactimport.ParseCSV(strings.NewReader("firstName,lastName,city\nAda,Lovelace,\n,,\n,,London\nada,LOVELACE,\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act; nothing is inserted when a row fails, so no database is needed
+	response, err := importRecords(database.DB{}, records, importModeAtomic)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Committed {
+		t.Error("committed, want: false got: true")
+	}
+	if response.Created != 0 || response.Skipped != 3 || response.Failed != 1 {
+		t.Errorf("counts, want: 0/3/1 got: %d/%d/%d", response.Created, response.Skipped, response.Failed)
+	}
+
+	want := []struct {
+		row     int
+		status  string
+		message string
+	}{
+		{2, importStatusSkipped, "not imported because other records failed"},
+		{3, importStatusSkipped, "empty record"},
+		{4, importStatusFailed, "firstName or lastName is required"},
+		{5, importStatusSkipped, "duplicate of row 2"},
+	}
+	for i, w := range want {
+		got := response.Rows[i]
+		if got.Row != w.row || got.Status != w.status || !strings.HasPrefix(got.Message, w.message) {
+			t.Errorf("row %d, want: %d %s %q got: %d %s %q", i, w.row, w.status, w.message, got.Row, got.Status, got.Message)
+		}
+	}
+}
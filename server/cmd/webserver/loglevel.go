@@ -0,0 +1,26 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+)
+
+// handleLogLevel reports the log level on GET and changes it on PUT, see log.LevelHandler. It
+// requires the --log-level-token as a bearer token, and doesn't exist without one.
+func (ws *webserver) handleLogLevel(w http.ResponseWriter, r *http.Request) error {
+	if ws.logLevelToken == "" {
+		return &notFound{}
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(ws.logLevelToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		return &unauthorized{message: "a valid bearer token is required"}
+	}
+
+	log.LevelHandler().ServeHTTP(w, r)
+	return nil
+}
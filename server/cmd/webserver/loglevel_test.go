@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleLogLevel_RequiresToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{name: "disabled without a token", authorization: "Bearer secret", want: http.StatusNotFound},
+		{name: "no credentials", token: "secret", want: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", authorization: "Bearer guess", want: http.StatusUnauthorized},
+		{name: "not a bearer token", token: "secret", authorization: "secret", want: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", authorization: "Bearer secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		ws := newTestWebserver()
+		ws.logLevelToken = tt.token
+		req := httptest.NewRequest("GET", "/log/level", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+
+		// act
+		ws.router().ServeHTTP(rec, req)
+
+		// assert
+		if rec.Code != tt.want {
+			t.Errorf("%s: status, want: %d got: %d %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandleLogLevel_UnauthorizedCannotChangeLevel(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.logLevelToken = "secret"
+	req := httptest.NewRequest("PUT", "/log/level", strings.NewReader(`{"level":"fatal"}`))
+	rec := httptest.NewRecorder()
+
+	// act
+	ws.router().ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status, want: 401 got: %d", rec.Code)
+	}
+	get := httptest.NewRequest("GET", "/log/level", nil)
+	get.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	ws.router().ServeHTTP(rec, get)
+	if strings.Contains(rec.Body.String(), "fatal") {
+		t.Errorf("level, want: unchanged got: %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/tracing"
+	"go.uber.org/zap"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	app = kingpin.New("skeleton", "A skeleton REST API that uses Postgres.")
+
+	flagListen     = app.Flag("listen", "The HTTP listen address.").Default("127.0.0.1:8423").String()
+	flagDBHost     = app.Flag("db-host", "The database host.").Default("127.0.0.1").String()
+	flagDBPort     = app.Flag("db-port", "The database port.").Default("5432").Int()
+	flagDBUser     = app.Flag("db-user", "The database user.").Default("vice_boilerplate_user").String()
+	flagDBPassword = app.Flag("db-password", "The database user's password.").Default("vicesoftware").String()
+	flagDBName     = app.Flag("db-name", "The database name.").Default("vice_boilerplate").String()
+	flagDBSSL      = app.Flag("db-ssl", "The database SSL mode.").Default("disable").String()
+
+	flagDBMaxOpenConns    = app.Flag("db-max-open-conns", "The maximum number of open database connections.").Default("25").Int()
+	flagDBMaxIdleConns    = app.Flag("db-max-idle-conns", "The maximum number of idle database connections.").Default("5").Int()
+	flagDBConnMaxLifetime = app.Flag("db-conn-max-lifetime", "The maximum amount of time a database connection may be reused.").Default("5m").Duration()
+	flagDBConnectRetries  = app.Flag("db-connect-retries", "The number of times to retry connecting to the database at startup.").Default("5").Int()
+	flagDBConnectBackoff  = app.Flag("db-connect-backoff", "The initial delay between database connection attempts; doubled after each failure.").Default("1s").Duration()
+	flagDBMigrate         = app.Flag("db-migrate", "Apply pending database migrations at startup.").Default("true").Bool()
+
+	flagGeocoder     = app.Flag("geocoder", "How addresses are geocoded: offline, from a table of postal code centroids, or none.").Default(geocoderOffline).Enum(geocoderOffline, geocoderNone)
+	flagGeocoderData = app.Flag("geocoder-data", "A GeoNames postal code file for the offline geocoder; empty uses the small sample built in.").String()
+
+	flagShutdownDelay   = app.Flag("shutdown-delay", "How long /readyz fails before the server stops accepting connections on shutdown.").Default("5s").Duration()
+	flagShutdownTimeout = app.Flag("shutdown-timeout", "The maximum time to wait for in-flight requests on shutdown.").Default("30s").Duration()
+
+	flagLogLevel            = app.Flag("log-level", "The minimum level to log: debug, info, warn or error.").Default("info").Enum("debug", "info", "warn", "error")
+	flagLogFormat           = app.Flag("log-format", "The log encoding: logfmt, json or console.").Default(log.EncodingLogfmt).Enum(log.EncodingLogfmt, log.EncodingJSON, log.EncodingConsole)
+	flagLogFile             = app.Flag("log-file", "Write logs to this file instead of stdout.").String()
+	flagLogMaxSize          = app.Flag("log-max-size", "The size in megabytes at which the log file is rotated.").Default("100").Int()
+	flagLogMaxBackups       = app.Flag("log-max-backups", "The number of rotated log files to keep; 0 keeps them all.").Default("5").Int()
+	flagLogMaxAge           = app.Flag("log-max-age", "The number of days to keep rotated log files; 0 keeps them forever.").Default("30").Int()
+	flagLogSampleInitial    = app.Flag("log-sample-initial", "Log the first N identical debug/info lines each second; 0 disables sampling.").Default("0").Int()
+	flagLogSampleThereafter = app.Flag("log-sample-thereafter", "After --log-sample-initial, log every Nth identical debug/info line each second.").Default("100").Int()
+	flagLogLevelToken       = app.Flag("log-level-token", "The bearer token required to read or change the log level at /log/level; empty disables the endpoint.").Envar("LOG_LEVEL_TOKEN").String()
+
+	flagAccessLogQueue    = app.Flag("access-log-queue", "The number of access log entries that can be queued for writing.").Default("1024").Int()
+	flagAccessLogPolicy   = app.Flag("access-log-policy", "What to do when the access log queue is full: drop the entry, or block the request until there's room.").Default(accessLogPolicyDrop).Enum(accessLogPolicyDrop, accessLogPolicyBlock)
+	flagAccessLogCombined = app.Flag("access-log-combined", "Also write the access log to this file in Apache/NCSA combined format; - for stdout.").String()
+
+	flagTrustedProxies = app.Flag("trusted-proxy", "The IP or CIDR of reverse proxies allowed to set Forwarded, X-Real-IP and X-Forwarded-For; repeatable.").Default("127.0.0.0/8", "::1").Strings()
+
+	flagRateLimitRead   = app.Flag("rate-limit-read", "The default limit per client for GET requests to /api/v1, as requests/period; off disables it.").Default("600/1m").String()
+	flagRateLimitWrite  = app.Flag("rate-limit-write", "The default limit per client for POST, PUT and DELETE requests to /api/v1, as requests/period; off disables it.").Default("60/1m").String()
+	flagRateLimitRoutes = app.Flag("rate-limit-route", "Override the limit for one route, e.g. 'POST /api/v1/contacts=10/1m'; repeatable.").StringMap()
+
+	flagCORSOrigins     = app.Flag("cors-origin", "An origin allowed to call /api/v1 from a browser, or * for any; repeatable.").Default("http://localhost:3000").Strings()
+	flagCORSMethods     = app.Flag("cors-method", "A method cross-origin requests may use; repeatable.").Default("GET", "POST", "PUT", "DELETE").Strings()
+	flagCORSHeaders     = app.Flag("cors-header", "A request header cross-origin requests may send, or * for any; repeatable.").Default("Content-Type", "Authorization", "X-Request-ID").Strings()
+	flagCORSExpose      = app.Flag("cors-expose-header", "A response header cross-origin clients may read; repeatable.").Default("X-Request-ID", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset").Strings()
+	flagCORSCredentials = app.Flag("cors-credentials", "Allow cross-origin requests to include cookies and Authorization.").Default("false").Bool()
+	flagCORSMaxAge      = app.Flag("cors-max-age", "How long browsers may cache a preflight response.").Default("10m").Duration()
+
+	flagMaxBodySize   = app.Flag("max-body-size", "The largest request body accepted, in bytes; 0 for no limit.").Default("1048576").Int64()
+	flagMaxImportSize = app.Flag("max-import-size", "The largest contact import file accepted, in bytes; 0 for no limit.").Default("10485760").Int64()
+
+	flagHSTSMaxAge            = app.Flag("hsts-max-age", "The Strict-Transport-Security max-age; 0 omits the header.").Default("8760h").Duration()
+	flagHSTSIncludeSubdomains = app.Flag("hsts-include-subdomains", "Apply Strict-Transport-Security to subdomains too.").Default("false").Bool()
+	flagFrameOptions          = app.Flag("frame-options", "The X-Frame-Options header; empty omits it.").Default("DENY").String()
+	flagReferrerPolicy        = app.Flag("referrer-policy", "The Referrer-Policy header; empty omits it.").Default("no-referrer").String()
+	flagCSP                   = app.Flag("csp", "The Content-Security-Policy header; empty omits it.").Default("default-src 'none'; frame-ancestors 'none'").String()
+	flagSwaggerCSP            = app.Flag("swagger-csp", "The Content-Security-Policy header for the /swagger/ UI; empty omits it.").Default("default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; font-src 'self' https://fonts.gstatic.com; img-src 'self' data:; frame-ancestors 'none'").String()
+
+	flagTracingExporter     = app.Flag("tracing-exporter", "Where to export trace spans: none, stdout or otlp.").Default(tracing.ExporterNone).Enum(tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP)
+	flagTracingOTLPEndpoint = app.Flag("tracing-otlp-endpoint", "The host:port of the collector's OTLP/HTTP receiver.").Default("localhost:4318").String()
+	flagTracingOTLPInsecure = app.Flag("tracing-otlp-insecure", "Connect to the OTLP collector over http rather than https.").Default("true").Bool()
+	flagTracingServiceName  = app.Flag("tracing-service-name", "The service.name reported on every span.").Default("webserver").String()
+	flagTracingSampleRatio  = app.Flag("tracing-sample-ratio", "The fraction of new traces to sample, between 0 and 1.").Default("1").Float64()
+)
+
+// @title Vice Software Example API
+// @version 1
+// @BasePath /api/v1
+
+func main() {
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	err := log.Configure(log.Config{
+		Level:            *flagLogLevel,
+		Encoding:         *flagLogFormat,
+		File:             *flagLogFile,
+		MaxSizeMB:        *flagLogMaxSize,
+		MaxBackups:       *flagLogMaxBackups,
+		MaxAgeDays:       *flagLogMaxAge,
+		SampleInitial:    *flagLogSampleInitial,
+		SampleThereafter: *flagLogSampleThereafter,
+	})
+	if err != nil {
+		log.Fatal("invalid log configuration", zap.Error(err))
+	}
+	defer log.Sync()
+
+	dbSettings := database.Settings{
+		Host:     *flagDBHost,
+		Port:     *flagDBPort,
+		User:     *flagDBUser,
+		Password: *flagDBPassword,
+		DBName:   *flagDBName,
+		SSLMode:  *flagDBSSL,
+
+		MaxOpenConns:    *flagDBMaxOpenConns,
+		MaxIdleConns:    *flagDBMaxIdleConns,
+		ConnMaxLifetime: *flagDBConnMaxLifetime,
+		ConnectRetries:  *flagDBConnectRetries,
+		ConnectBackoff:  *flagDBConnectBackoff,
+	}
+
+	dbSettings.Geocoder, err = newGeocoder(*flagGeocoder, *flagGeocoderData)
+	if err != nil {
+		log.Fatal("geocoder setup failed", zap.Error(err))
+	}
+
+	shutdownTracing, err := tracing.Init(tracing.Settings{
+		Exporter:     *flagTracingExporter,
+		OTLPEndpoint: *flagTracingOTLPEndpoint,
+		OTLPInsecure: *flagTracingOTLPInsecure,
+		ServiceName:  *flagTracingServiceName,
+		SampleRatio:  *flagTracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatal("tracing setup failed", zap.Error(err))
+	}
+
+	log.Info("connecting to the database...")
+
+	db, err := database.New(dbSettings)
+	if err != nil {
+		log.Fatal("database connection failed", zap.Error(err))
+	}
+
+	if *flagDBMigrate {
+		if err := db.Migrate(); err != nil {
+			log.Fatal("database migration failed", zap.Error(err))
+		}
+	}
+
+	var combinedLog io.Writer
+	switch *flagAccessLogCombined {
+	case "":
+	case "-":
+		combinedLog = os.Stdout
+	default:
+		f, err := os.OpenFile(*flagAccessLogCombined, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Fatal("opening combined access log failed", zap.Error(err))
+		}
+		defer f.Close()
+		combinedLog = f
+	}
+
+	clientIPs, err := clientip.NewResolver(*flagTrustedProxies)
+	if err != nil {
+		log.Fatal("invalid trusted proxy", zap.Error(err))
+	}
+
+	limits, err := parseRateLimits(*flagRateLimitRead, *flagRateLimitWrite, *flagRateLimitRoutes)
+	if err != nil {
+		log.Fatal("invalid rate limit", zap.Error(err))
+	}
+
+	ws := webserver{
+		addr:            *flagListen,
+		db:              db,
+		accessLog:       newAccessLogger(*flagAccessLogQueue, *flagAccessLogPolicy, combinedLog),
+		shutdownDelay:   *flagShutdownDelay,
+		shutdownTimeout: *flagShutdownTimeout,
+		clientIPs:       clientIPs,
+		rateLimits:      limits,
+		maxBodyBytes:    *flagMaxBodySize,
+		maxImportBytes:  *flagMaxImportSize,
+		logLevelToken:   *flagLogLevelToken,
+		security: securityHeaders{
+			hstsMaxAge:                   *flagHSTSMaxAge,
+			hstsIncludeSubdomains:        *flagHSTSIncludeSubdomains,
+			frameOptions:                 *flagFrameOptions,
+			referrerPolicy:               *flagReferrerPolicy,
+			contentSecurityPolicy:        *flagCSP,
+			swaggerContentSecurityPolicy: *flagSwaggerCSP,
+		},
+		cors: corsConfig{
+			allowedOrigins:   *flagCORSOrigins,
+			allowedMethods:   *flagCORSMethods,
+			allowedHeaders:   *flagCORSHeaders,
+			exposedHeaders:   *flagCORSExpose,
+			allowCredentials: *flagCORSCredentials,
+			maxAge:           *flagCORSMaxAge,
+		},
+	}
+	ws.Start()
+
+	// flush any spans still buffered by the exporter
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Error("tracing shutdown failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that didn't match a registered route, so scanners probing
+// random URIs can't create unbounded label values.
+const unmatchedRoute = "unmatched"
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "http",
+		Name:      "requests_total",
+		Help:      "Number of HTTP requests handled, by route template, method and status class.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests, by route template, method and status class.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+// metricsHandler serves the HTTP metrics, the database's query and pool metrics and the standard
+// Go runtime and process collectors.
+func (ws *webserver) metricsHandler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+	)
+	registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: "http",
+		Name:      "access_log_dropped_total",
+		Help:      "Number of access log entries dropped because the queue was full.",
+	}, func() float64 {
+		return float64(ws.accessLog.Dropped())
+	}))
+	registry.MustRegister(ws.db.Collectors()...)
+
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// observeHTTPRequest records the request in the HTTP metrics. It's labelled by the mux route
+// template (e.g. /api/v1/contacts/{contactID}) rather than the raw URI to keep cardinality bounded.
+func observeHTTPRequest(r *http.Request, duration time.Duration, status int) {
+	route := routeTemplate(r)
+	statusClass := strconv.Itoa(status/100) + "xx"
+
+	httpRequestsTotal.WithLabelValues(route, r.Method, statusClass).Inc()
+	httpRequestDuration.WithLabelValues(route, r.Method, statusClass).Observe(duration.Seconds())
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+	"go.uber.org/zap"
+)
+
+// middleware wraps an http.Handler; the router is wrapped in these in Start so they apply to
+// every request, including ones that match no route.
+type middleware func(http.Handler) http.Handler
+
+// chain applies middlewares so the first one listed is the outermost.
+func chain(h http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// requestID reuses the caller's X-Request-ID when it's valid, otherwise generates one. The ID is
+// stored in the request context, added to lines logged with log.FromContext and echoed in the
+// response so client reports can be matched to log lines.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.IsValid(id) {
+			id = requestid.New()
+		}
+
+		ctx := requestid.NewContext(r.Context(), id)
+		ctx = log.NewContext(ctx, zap.String("request_id", id))
+
+		w.Header().Set(requestid.Header, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// resolveClientIP resolves the client IP once, trusting only the configured proxies' forwarding
+// headers, and stores it in the request context so logging, rate limiting and auditing agree on it.
+func (ws *webserver) resolveClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := clientip.NewContext(r.Context(), ws.clientIPs.ClientIP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
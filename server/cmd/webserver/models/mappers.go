@@ -0,0 +1,297 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/health"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+const (
+	healthStatusOk      = "ok"
+	healthStatusFailing = "failing"
+)
+
+func MapHealthResponse(report health.Report) HealthResponse {
+	resp := HealthResponse{
+		Status: healthStatusOk,
+		Checks: make([]HealthCheckResponse, 0, len(report.Checks)),
+	}
+	if !report.Healthy {
+		resp.Status = healthStatusFailing
+	}
+
+	for _, check := range report.Checks {
+		checkResp := HealthCheckResponse{
+			Name:      check.Name,
+			Status:    healthStatusOk,
+			TimeTaken: int64(check.Duration / time.Millisecond),
+		}
+		if check.Err != nil {
+			checkResp.Status = healthStatusFailing
+			checkResp.Error = check.Err.Error()
+		}
+		resp.Checks = append(resp.Checks, checkResp)
+	}
+
+	return resp
+}
+
+func MapContactResponse(contact database.Contact, addresses []database.Address) ContactResponse {
+	resp := ContactResponse{
+		ID:        contact.ID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Company:   contact.Company,
+		Title:     contact.Title,
+		Notes:     contact.Notes,
+		Addresses: MapContactAddresses(addresses),
+		CreatedAt: toMS(contact.CreatedAt),
+		UpdatedAt: toMS(contact.UpdatedAt),
+	}
+	for i := range resp.Addresses {
+		if resp.Addresses[i].IsPrimary {
+			primary := resp.Addresses[i]
+			resp.PrimaryAddress = &primary
+			break
+		}
+	}
+	return resp
+}
+
+func MapContactSummaryResponse(contact database.Contact) ContactSummaryResponse {
+	return ContactSummaryResponse{
+		ID:        contact.ID,
+		FirstName: contact.FirstName,
+		LastName:  contact.LastName,
+		Company:   contact.Company,
+	}
+}
+
+func MapDuplicateResponses(pairs []database.DuplicatePair) []DuplicateResponse {
+	resp := make([]DuplicateResponse, 0, len(pairs))
+	for _, pair := range pairs {
+		resp = append(resp, DuplicateResponse{
+			Contact:        MapContactSummaryResponse(pair.Contacts[0]),
+			Duplicate:      MapContactSummaryResponse(pair.Contacts[1]),
+			NameSimilarity: math.Round(pair.NameSimilarity*1000) / 1000,
+			SimilarName:    pair.SimilarName,
+			SharedAddress:  pair.SharedAddress,
+			SharedEmail:    pair.SharedEmail,
+		})
+	}
+	return resp
+}
+
+func MapAuditEntryResponses(entries []database.AuditEntry) []AuditEntryResponse {
+	resp := make([]AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		resp = append(resp, AuditEntryResponse{
+			ID:        entry.ID,
+			Action:    entry.Action,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+			ContactID: entry.ContactID,
+			Actor:     entry.Actor,
+			RequestID: entry.RequestID,
+			IP:        entry.IP,
+			Before:    jsonObjectOrEmpty(entry.Before),
+			After:     jsonObjectOrEmpty(entry.After),
+			Details:   jsonObjectOrEmpty(entry.Details),
+			CreatedAt: toMS(entry.CreatedAt),
+		})
+	}
+	return resp
+}
+
+func MapContactAddresses(addresses []database.Address) []AddressResponse {
+	resp := make([]AddressResponse, 0, len(addresses))
+	for _, address := range addresses {
+		resp = append(resp, MapContactAddress(address))
+	}
+	return resp
+}
+
+func MapContactAddress(address database.Address) AddressResponse {
+	return AddressResponse{
+		ID:            address.ID,
+		Line1:         address.Line1,
+		Line2:         address.Line2,
+		City:          address.City,
+		StateProvince: address.StateProvince,
+		PostalCode:    address.PostalCode,
+		Country:       address.Country,
+		FormattedAddress: postal.Format(postal.Address{
+			Line1:         address.Line1,
+			Line2:         StringOrEmpty(address.Line2),
+			City:          address.City,
+			StateProvince: address.StateProvince,
+			PostalCode:    address.PostalCode,
+			Country:       address.Country,
+		}),
+		Lat:       address.Lat,
+		Lng:       address.Lng,
+		IsPrimary: address.IsPrimary,
+		CreatedAt: toMS(address.CreatedAt),
+		UpdatedAt: toMS(address.UpdatedAt),
+	}
+}
+
+func MapNearbyContactResponses(nearby []database.NearbyContact) []NearbyContactResponse {
+	resp := make([]NearbyContactResponse, 0, len(nearby))
+	for _, n := range nearby {
+		resp = append(resp, NearbyContactResponse{
+			ID:        n.Contact.ID,
+			FirstName: n.Contact.FirstName,
+			LastName:  n.Contact.LastName,
+			Distance:  math.Round(n.Distance*1000) / 1000, // to the metre
+			Address:   MapContactAddress(n.Address),
+		})
+	}
+	return resp
+}
+
+func MapCreateContactRequest(request ContactRequest) database.Contact {
+	return database.Contact{
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Company:   request.Company,
+		Title:     request.Title,
+		Notes:     request.Notes,
+	}
+}
+
+func MapUpdateContactRequest(contactID int, request ContactRequest) database.Contact {
+	return database.Contact{
+		ID:        contactID,
+		FirstName: request.FirstName,
+		LastName:  request.LastName,
+		Company:   request.Company,
+		Title:     request.Title,
+		Notes:     request.Notes,
+	}
+}
+
+func MapCreateAddressRequest(contactID int, request AddressRequest) database.Address {
+	return database.Address{
+		ContactID:     contactID,
+		Line1:         request.Line1,
+		Line2:         request.Line2,
+		City:          request.City,
+		StateProvince: request.StateProvince,
+		PostalCode:    request.PostalCode,
+		Country:       request.Country,
+		IsPrimary:     request.IsPrimary,
+	}
+}
+
+// MapCreateAddressRequests keeps a nil slice nil, see database.ContactProvider.UpdateWithAddresses.
+func MapCreateAddressRequests(contactID int, requests []AddressRequest) []database.Address {
+	if requests == nil {
+		return nil
+	}
+	addresses := make([]database.Address, 0, len(requests))
+	for _, request := range requests {
+		addresses = append(addresses, MapCreateAddressRequest(contactID, request))
+	}
+	return addresses
+}
+
+func MapUpdateAddressRequest(contactID, addressID int, request AddressRequest) database.Address {
+	return database.Address{
+		ID:            addressID,
+		ContactID:     contactID,
+		Line1:         request.Line1,
+		Line2:         request.Line2,
+		City:          request.City,
+		StateProvince: request.StateProvince,
+		PostalCode:    request.PostalCode,
+		Country:       request.Country,
+		IsPrimary:     request.IsPrimary,
+	}
+}
+
+func MapContactEmails(emails []database.Email) []EmailResponse {
+	resp := make([]EmailResponse, 0, len(emails))
+	for _, email := range emails {
+		resp = append(resp, MapContactEmail(email))
+	}
+	return resp
+}
+
+func MapContactEmail(email database.Email) EmailResponse {
+	return EmailResponse{
+		ID:        email.ID,
+		Type:      email.Type,
+		Address:   email.Address,
+		IsPrimary: email.IsPrimary,
+		CreatedAt: toMS(email.CreatedAt),
+		UpdatedAt: toMS(email.UpdatedAt),
+	}
+}
+
+func MapEmailRequest(contactID, emailID int, request EmailRequest) database.Email {
+	return database.Email{
+		ID:        emailID,
+		ContactID: contactID,
+		Type:      request.Type,
+		Address:   request.Address,
+		IsPrimary: request.IsPrimary,
+	}
+}
+
+func MapContactPhones(phones []database.Phone) []PhoneResponse {
+	resp := make([]PhoneResponse, 0, len(phones))
+	for _, phone := range phones {
+		resp = append(resp, MapContactPhone(phone))
+	}
+	return resp
+}
+
+func MapContactPhone(phone database.Phone) PhoneResponse {
+	return PhoneResponse{
+		ID:        phone.ID,
+		Type:      phone.Type,
+		Number:    phone.Number,
+		IsPrimary: phone.IsPrimary,
+		CreatedAt: toMS(phone.CreatedAt),
+		UpdatedAt: toMS(phone.UpdatedAt),
+	}
+}
+
+func MapPhoneRequest(contactID, phoneID int, request PhoneRequest) database.Phone {
+	return database.Phone{
+		ID:        phoneID,
+		ContactID: contactID,
+		Type:      request.Type,
+		Number:    request.Number,
+		IsPrimary: request.IsPrimary,
+	}
+}
+
+func MapContactTags(tags []database.Tag) []TagResponse {
+	resp := make([]TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp = append(resp, MapContactTag(tag))
+	}
+	return resp
+}
+
+func MapContactTag(tag database.Tag) TagResponse {
+	return TagResponse{
+		ID:        tag.ID,
+		Name:      tag.Name,
+		CreatedAt: toMS(tag.CreatedAt),
+		UpdatedAt: toMS(tag.UpdatedAt),
+	}
+}
+
+func MapTagRequest(contactID, tagID int, request TagRequest) database.Tag {
+	return database.Tag{
+		ID:        tagID,
+		ContactID: contactID,
+		Name:      request.Name,
+	}
+}
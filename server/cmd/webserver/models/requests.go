@@ -0,0 +1,62 @@
+package models
+
+// ContactRequest creates or updates a contact. Addresses replaces the contact's addresses; when
+// it's left out of an update the addresses are unchanged.
+type ContactRequest struct {
+	FirstName string           `json:"firstName" xml:"firstName" example:"John"`
+	LastName  string           `json:"lastName" xml:"lastName" example:"Doe"`
+	Company   *string          `json:"company,omitempty" xml:"company,omitempty" example:"Acme Corp."`
+	Title     *string          `json:"title,omitempty" xml:"title,omitempty" example:"Purchasing Manager"`
+	Notes     *string          `json:"notes,omitempty" xml:"notes,omitempty" example:"Prefers email."`
+	Addresses []AddressRequest `json:"addresses,omitempty" xml:"addresses>address,omitempty"`
+}
+
+type AddressRequest struct {
+	Line1         string  `json:"line1" xml:"line1" example:"1600 Pennsylvania Ave."`
+	Line2         *string `json:"line2,omitempty" xml:"line2,omitempty" example:"Ste. 1234"`
+	City          string  `json:"city" xml:"city" example:"Washington"`
+	StateProvince string  `json:"stateProvince" xml:"stateProvince" example:"DC"`
+	PostalCode    string  `json:"postalCode" xml:"postalCode" example:"20006"`
+	// Country is an ISO 3166-1 alpha-2 code or English name. It defaults to US on create and to
+	// the address's current country on update.
+	Country string `json:"country,omitempty" xml:"country,omitempty" example:"US"`
+	// IsPrimary makes this the contact's primary address. A contact's first address is always its
+	// primary, and the primary only changes when another address is made primary.
+	IsPrimary bool `json:"isPrimary" xml:"isPrimary" example:"true"`
+}
+
+type EmailRequest struct {
+	Type      string `json:"type" xml:"type" example:"work" enums:"work,home,other"`
+	Address   string `json:"address" xml:"address" example:"john.doe@example.com"`
+	IsPrimary bool   `json:"isPrimary" xml:"isPrimary" example:"true"`
+}
+
+type PhoneRequest struct {
+	Type      string `json:"type" xml:"type" example:"mobile" enums:"work,home,mobile,other"`
+	Number    string `json:"number" xml:"number" example:"+1 202 555 0123"`
+	IsPrimary bool   `json:"isPrimary" xml:"isPrimary" example:"true"`
+}
+
+type TagRequest struct {
+	Name string `json:"name" xml:"name" example:"customer"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" xml:"operations>operation"`
+}
+
+// BatchOperation creates, updates or deletes a contact or an address. ID and ContactID are either
+// a number or a reference to the result of an earlier operation, such as "$0.id".
+type BatchOperation struct {
+	Op        string          `json:"op" xml:"op" example:"create" enums:"create,update,delete"`
+	Type      string          `json:"type" xml:"type" example:"address" enums:"contact,address"`
+	ID        string          `json:"id,omitempty" xml:"id,omitempty" example:"12"`
+	ContactID string          `json:"contactId,omitempty" xml:"contactId,omitempty" example:"$0.id"`
+	Contact   *ContactRequest `json:"contact,omitempty" xml:"contact,omitempty"`
+	Address   *AddressRequest `json:"address,omitempty" xml:"address,omitempty"`
+}
+
+// MergeRequest merges the contact with SourceID into the contact in the URL, deleting the source.
+type MergeRequest struct {
+	SourceID int `json:"sourceId" xml:"sourceId" example:"2"`
+}
@@ -0,0 +1,162 @@
+package models
+
+type ErrorResponse struct {
+	Error     string `json:"error" xml:"error"`
+	RequestID string `json:"requestId,omitempty" xml:"requestId,omitempty" example:"4bf92f3580b34a1c9d5a0e3e2f1d7c6b"`
+}
+
+type PingResponse struct {
+	Message string `json:"msg" xml:"msg" example:"pong"`
+}
+
+type HealthResponse struct {
+	Status string                `json:"status" xml:"status" example:"ok"`
+	Checks []HealthCheckResponse `json:"checks" xml:"checks>check"`
+}
+
+type HealthCheckResponse struct {
+	Name      string `json:"name" xml:"name" example:"database"`
+	Status    string `json:"status" xml:"status" example:"ok"`
+	Error     string `json:"error,omitempty" xml:"error,omitempty"`
+	TimeTaken int64  `json:"timeTaken" xml:"timeTaken" example:"3"`
+}
+
+// ContactResponse is a contact and its addresses. PrimaryAddress is also one of Addresses, and is
+// left out if the contact has no addresses. Emails, Phones and Tags are only included when a single
+// contact is requested.
+type ContactResponse struct {
+	ID             int               `json:"id" xml:"id" example:"1"`
+	FirstName      string            `json:"firstName" xml:"firstName" example:"John"`
+	LastName       string            `json:"lastName" xml:"lastName" example:"Doe"`
+	Company        *string           `json:"company,omitempty" xml:"company,omitempty" example:"Acme Corp."`
+	Title          *string           `json:"title,omitempty" xml:"title,omitempty" example:"Purchasing Manager"`
+	Notes          *string           `json:"notes,omitempty" xml:"notes,omitempty" example:"Prefers email."`
+	PrimaryAddress *AddressResponse  `json:"primaryAddress,omitempty" xml:"primaryAddress,omitempty"`
+	Addresses      []AddressResponse `json:"addresses" xml:"addresses>address"`
+	Emails         []EmailResponse   `json:"emails,omitempty" xml:"emails>email,omitempty"`
+	Phones         []PhoneResponse   `json:"phones,omitempty" xml:"phones>phone,omitempty"`
+	Tags           []TagResponse     `json:"tags,omitempty" xml:"tags>tag,omitempty"`
+	CreatedAt      int64             `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+	UpdatedAt      int64             `json:"updatedAt" xml:"updatedAt" example:"1554441489907"`
+}
+
+type AddressResponse struct {
+	ID            int     `json:"id" xml:"id" example:"1"`
+	Line1         string  `json:"line1" xml:"line1" example:"1600 Pennsylvania Ave."`
+	Line2         *string `json:"line2,omitempty" xml:"line2,omitempty" example:"Ste. 1234"`
+	City          string  `json:"city" xml:"city" example:"Washington"`
+	StateProvince string  `json:"stateProvince" xml:"stateProvince" example:"DC"`
+	PostalCode    string  `json:"postalCode" xml:"postalCode" example:"20006"`
+	Country       string  `json:"country" xml:"country" example:"US"`
+	// FormattedAddress is the address as lines of a mailing label, in its country's conventions.
+	FormattedAddress string `json:"formattedAddress" xml:"formattedAddress" example:"1600 Pennsylvania Ave.\nSte. 1234\nWashington, DC 20006\nUNITED STATES"`
+	// Lat and Lng are left out until the address has been geocoded, or if it can't be.
+	Lat       *float64 `json:"lat,omitempty" xml:"lat,omitempty" example:"38.899"`
+	Lng       *float64 `json:"lng,omitempty" xml:"lng,omitempty" example:"-77.041"`
+	IsPrimary bool     `json:"isPrimary" xml:"isPrimary" example:"true"`
+	CreatedAt int64    `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+	UpdatedAt int64    `json:"updatedAt" xml:"updatedAt" example:"1554441489907"`
+}
+
+// NearbyContactResponse is a contact found near a point, with its address nearest the point and the
+// distance to it in kilometres.
+type NearbyContactResponse struct {
+	ID        int             `json:"id" xml:"id" example:"1"`
+	FirstName string          `json:"firstName" xml:"firstName" example:"John"`
+	LastName  string          `json:"lastName" xml:"lastName" example:"Doe"`
+	Distance  float64         `json:"distance" xml:"distance" example:"2.104"`
+	Address   AddressResponse `json:"address" xml:"address"`
+}
+
+// ContactSummaryResponse identifies a contact in lists of pairs, where its addresses would be noise.
+type ContactSummaryResponse struct {
+	ID        int     `json:"id" xml:"id" example:"1"`
+	FirstName string  `json:"firstName" xml:"firstName" example:"John"`
+	LastName  string  `json:"lastName" xml:"lastName" example:"Doe"`
+	Company   *string `json:"company,omitempty" xml:"company,omitempty" example:"Acme Corp."`
+}
+
+// DuplicateResponse is a pair of contacts that may be the same person. Duplicate is the newer of
+// the two, so the one usually merged into Contact.
+type DuplicateResponse struct {
+	Contact        ContactSummaryResponse `json:"contact" xml:"contact"`
+	Duplicate      ContactSummaryResponse `json:"duplicate" xml:"duplicate"`
+	NameSimilarity float64                `json:"nameSimilarity" xml:"nameSimilarity" example:"0.545"`
+	SimilarName    bool                   `json:"similarName" xml:"similarName" example:"true"`
+	SharedAddress  bool                   `json:"sharedAddress" xml:"sharedAddress" example:"false"`
+	SharedEmail    bool                   `json:"sharedEmail" xml:"sharedEmail" example:"false"`
+}
+
+// AuditEntryResponse is a change recorded in the audit log. Actor, RequestID and IP are those of the
+// request that made the change. Before and After are the entity's fields before and after it: Before
+// is left out of a create and After out of a delete, and an update only includes the fields that
+// changed. Details describes a merge.
+type AuditEntryResponse struct {
+	ID        int        `json:"id" xml:"id" example:"1"`
+	Action    string     `json:"action" xml:"action" example:"update"`
+	Entity    string     `json:"entity" xml:"entity" example:"contact"`
+	EntityID  int        `json:"entityId" xml:"entityId" example:"1"`
+	ContactID *int       `json:"contactId,omitempty" xml:"contactId,omitempty" example:"1"`
+	Actor     string     `json:"actor,omitempty" xml:"actor,omitempty" example:"user-1"`
+	RequestID string     `json:"requestId,omitempty" xml:"requestId,omitempty" example:"4bf92f3580b34a1c9d5a0e3e2f1d7c6b"`
+	IP        string     `json:"ip,omitempty" xml:"ip,omitempty" example:"203.0.113.7"`
+	Before    JSONObject `json:"before,omitempty" xml:"before,omitempty" swaggertype:"object"`
+	After     JSONObject `json:"after,omitempty" xml:"after,omitempty" swaggertype:"object"`
+	Details   JSONObject `json:"details,omitempty" xml:"details,omitempty" swaggertype:"object"`
+	CreatedAt int64      `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+}
+
+type EmailResponse struct {
+	ID        int    `json:"id" xml:"id" example:"1"`
+	Type      string `json:"type" xml:"type" example:"work"`
+	Address   string `json:"address" xml:"address" example:"john.doe@example.com"`
+	IsPrimary bool   `json:"isPrimary" xml:"isPrimary" example:"true"`
+	CreatedAt int64  `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+	UpdatedAt int64  `json:"updatedAt" xml:"updatedAt" example:"1554441489907"`
+}
+
+type PhoneResponse struct {
+	ID        int    `json:"id" xml:"id" example:"1"`
+	Type      string `json:"type" xml:"type" example:"mobile"`
+	Number    string `json:"number" xml:"number" example:"+1 202 555 0123"`
+	IsPrimary bool   `json:"isPrimary" xml:"isPrimary" example:"true"`
+	CreatedAt int64  `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+	UpdatedAt int64  `json:"updatedAt" xml:"updatedAt" example:"1554441489907"`
+}
+
+type TagResponse struct {
+	ID        int    `json:"id" xml:"id" example:"1"`
+	Name      string `json:"name" xml:"name" example:"customer"`
+	CreatedAt int64  `json:"createdAt" xml:"createdAt" example:"1554441489907"`
+	UpdatedAt int64  `json:"updatedAt" xml:"updatedAt" example:"1554441489907"`
+}
+
+type ImportResponse struct {
+	Mode      string              `json:"mode" xml:"mode" example:"atomic"`
+	Committed bool                `json:"committed" xml:"committed" example:"true"`
+	Created   int                 `json:"created" xml:"created" example:"1"`
+	Skipped   int                 `json:"skipped" xml:"skipped" example:"1"`
+	Failed    int                 `json:"failed" xml:"failed" example:"0"`
+	Rows      []ImportRowResponse `json:"rows" xml:"rows>row"`
+	Error     string              `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+type ImportRowResponse struct {
+	Row       int    `json:"row" xml:"row" example:"2"`
+	Status    string `json:"status" xml:"status" example:"created"`
+	ContactID int    `json:"contactId,omitempty" xml:"contactId,omitempty" example:"1"`
+	Message   string `json:"message,omitempty" xml:"message,omitempty"`
+}
+
+type BatchResponse struct {
+	Committed bool                  `json:"committed" xml:"committed" example:"true"`
+	Results   []BatchResultResponse `json:"results" xml:"results>result"`
+	Error     string                `json:"error,omitempty" xml:"error,omitempty"`
+}
+
+type BatchResultResponse struct {
+	Status  int              `json:"status" xml:"status" example:"200"`
+	Contact *ContactResponse `json:"contact,omitempty" xml:"contact,omitempty"`
+	Address *AddressResponse `json:"address,omitempty" xml:"address,omitempty"`
+	Error   string           `json:"error,omitempty" xml:"error,omitempty"`
+}
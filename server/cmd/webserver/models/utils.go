@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+func toMS(t time.Time) int64 {
+	return t.UTC().UnixNano() / int64(time.Millisecond)
+}
+
+// StringOrEmpty returns the string s points to, or "" if s is nil.
+func StringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// JSONObject is a JSON object held as text. It's written as the object in JSON responses and as
+// its text in the other formats.
+type JSONObject string
+
+func (o JSONObject) MarshalJSON() ([]byte, error) {
+	if o == "" {
+		return []byte("null"), nil
+	}
+	return []byte(o), nil
+}
+
+func jsonObjectOrEmpty(s *string) JSONObject {
+	return JSONObject(StringOrEmpty(s))
+}
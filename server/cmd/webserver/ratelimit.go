@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/auth"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/ratelimit"
+	"go.uber.org/zap"
+)
+
+// rateLimits configures the limits applied to /api/v1 routes.
+type rateLimits struct {
+	store ratelimit.Store
+	read  ratelimit.Limit // GET, HEAD and OPTIONS
+	write ratelimit.Limit // everything else
+
+	// routes overrides read and write for individual routes, keyed by method and route template,
+	// e.g. "POST /api/v1/contacts"
+	routes map[string]ratelimit.Limit
+}
+
+// limitFor returns the limit for the route the request matched.
+func (l rateLimits) limitFor(r *http.Request) ratelimit.Limit {
+	if limit, ok := l.routes[r.Method+" "+routeTemplate(r)]; ok {
+		return limit
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return l.read
+	default:
+		return l.write
+	}
+}
+
+// rateLimit is mux middleware that limits each client to the route's limit. Clients are the
+// authenticated user if there is one, otherwise the client IP. Every limited response carries the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers; rejected requests get a 429
+// with Retry-After.
+func (ws *webserver) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := ws.rateLimits.limitFor(r)
+		if ws.rateLimits.store == nil || !limit.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// buckets are per route, so a client hammering one endpoint isn't locked out of the rest
+		key := r.Method + " " + routeTemplate(r) + " " + ws.rateLimitClient(r)
+		result, err := ws.rateLimits.store.Take(r.Context(), key, limit)
+		if err != nil {
+			// fail open; an unavailable store shouldn't take the API down with it
+			log.FromContext(r.Context()).Warn("rate limit store failed", zap.Error(err))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+			ws.handler(func(http.ResponseWriter, *http.Request) error {
+				return &tooManyRequests{}
+			}).ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitClient identifies who a request counts against.
+func (ws *webserver) rateLimitClient(r *http.Request) string {
+	if userID := auth.UserID(r.Context()); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + ws.clientIP(r)
+}
+
+// ceilSeconds formats d as whole seconds, rounding up so clients never retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// parseRateLimits builds the limits from the --rate-limit-* flags, using an in-memory store.
+func parseRateLimits(read, write string, routes map[string]string) (rateLimits, error) {
+	limits := rateLimits{
+		store:  ratelimit.NewMemoryStore(),
+		routes: make(map[string]ratelimit.Limit, len(routes)),
+	}
+
+	var err error
+	if limits.read, err = ratelimit.ParseLimit(read); err != nil {
+		return rateLimits{}, err
+	}
+	if limits.write, err = ratelimit.ParseLimit(write); err != nil {
+		return rateLimits{}, err
+	}
+	for route, limit := range routes {
+		if limits.routes[route], err = ratelimit.ParseLimit(limit); err != nil {
+			return rateLimits{}, err
+		}
+	}
+	return limits, nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/auth"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/ratelimit"
+)
+
+func newRateLimitedRouter(ws *webserver) *mux.Router {
+	r := mux.NewRouter()
+	apiv1 := r.PathPrefix("/api/v1").Subrouter()
+	apiv1.Use(ws.rateLimit)
+	apiv1.HandleFunc("/ping", ws.handler(ws.handlePing)).Methods("GET")
+	apiv1.HandleFunc("/ping", ws.handler(ws.handlePing)).Methods("POST")
+	return r
+}
+
+func TestRateLimit_RejectsWithRetryAfter(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.rateLimits = rateLimits{
+		store: ratelimit.NewMemoryStore(),
+		read:  ratelimit.Limit{Rate: 1, Burst: 2},
+	}
+	router := newRateLimitedRouter(ws)
+
+	// act
+	var codes []int
+	var last *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		last = httptest.NewRecorder()
+		router.ServeHTTP(last, httptest.NewRequest("GET", "/api/v1/ping", nil))
+		codes = append(codes, last.Code)
+	}
+
+	// assert
+	want := []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("codes[%d], want: %d got: %d", i, want[i], codes[i])
+		}
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "2",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "2",
+		"Retry-After":         "1",
+	} {
+		if got := last.Header().Get(header); got != want {
+			t.Errorf("%s, want: %q got: %q", header, want, got)
+		}
+	}
+}
+
+func TestRateLimit_RouteOverrideAndClientsAreSeparate(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.rateLimits = rateLimits{
+		store:  ratelimit.NewMemoryStore(),
+		read:   ratelimit.Limit{Rate: 1, Burst: 100},
+		routes: map[string]ratelimit.Limit{"GET /api/v1/ping": {Rate: 1, Burst: 1}},
+	}
+	router := newRateLimitedRouter(ws)
+
+	first := httptest.NewRequest("GET", "/api/v1/ping", nil)
+	first.RemoteAddr = "10.0.0.1:1234"
+	second := httptest.NewRequest("GET", "/api/v1/ping", nil)
+	second.RemoteAddr = "10.0.0.1:1234"
+	user := httptest.NewRequest("GET", "/api/v1/ping", nil)
+	user.RemoteAddr = "10.0.0.1:1234"
+	user = user.WithContext(auth.NewContext(user.Context(), "42"))
+
+	// act
+	codes := make([]int, 0, 3)
+	for _, req := range []*http.Request{first, second, user} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	// assert
+	want := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("codes[%d], want: %d got: %d", i, want[i], codes[i])
+		}
+	}
+}
+
+func TestRateLimit_SpoofedForwardingHeadersShareTheBucket(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.clientIPs, _ = clientip.NewResolver([]string{"127.0.0.1"})
+	ws.rateLimits = rateLimits{
+		store: ratelimit.NewMemoryStore(),
+		read:  ratelimit.Limit{Rate: 1, Burst: 1},
+	}
+	h := chain(newRateLimitedRouter(ws), ws.resolveClientIP)
+
+	// act
+	codes := make([]int, 0, 2)
+	for _, spoofed := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest("GET", "/api/v1/ping", nil)
+		req.RemoteAddr = "203.0.113.7:5000"
+		req.Header.Set("X-Forwarded-For", spoofed)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+
+	// assert
+	want := []int{http.StatusOK, http.StatusTooManyRequests}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("codes[%d], want: %d got: %d", i, want[i], codes[i])
+		}
+	}
+}
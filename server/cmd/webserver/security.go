@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// securityHeaders are added to every response.
+type securityHeaders struct {
+	hstsMaxAge            time.Duration // Strict-Transport-Security max-age, 0 to omit the header
+	hstsIncludeSubdomains bool
+	frameOptions          string // X-Frame-Options, e.g. DENY
+	referrerPolicy        string
+	contentSecurityPolicy string
+
+	// swaggerContentSecurityPolicy replaces contentSecurityPolicy under /swagger/, since the UI
+	// needs inline scripts and styles and Google Fonts where the JSON API needs nothing at all
+	swaggerContentSecurityPolicy string
+}
+
+// addSecurityHeaders adds the security headers to every response, including errors and paths
+// that match no route.
+func (ws *webserver) addSecurityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+
+		// browsers ignore HSTS received over plain http, so it's harmless before TLS is set up
+		if ws.security.hstsMaxAge > 0 {
+			hsts := "max-age=" + strconv.Itoa(int(ws.security.hstsMaxAge.Seconds()))
+			if ws.security.hstsIncludeSubdomains {
+				hsts += "; includeSubDomains"
+			}
+			h.Set("Strict-Transport-Security", hsts)
+		}
+
+		h.Set("X-Content-Type-Options", "nosniff")
+		if ws.security.frameOptions != "" {
+			h.Set("X-Frame-Options", ws.security.frameOptions)
+		}
+		if ws.security.referrerPolicy != "" {
+			h.Set("Referrer-Policy", ws.security.referrerPolicy)
+		}
+
+		csp := ws.security.contentSecurityPolicy
+		if strings.HasPrefix(r.URL.Path, "/swagger/") {
+			csp = ws.security.swaggerContentSecurityPolicy
+		}
+		if csp != "" {
+			h.Set("Content-Security-Policy", csp)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
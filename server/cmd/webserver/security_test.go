@@ -0,0 +1,49 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAddSecurityHeaders(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	ws.security = securityHeaders{
+		hstsMaxAge:                   time.Hour,
+		hstsIncludeSubdomains:        true,
+		frameOptions:                 "DENY",
+		referrerPolicy:               "no-referrer",
+		contentSecurityPolicy:        "default-src 'none'",
+		swaggerContentSecurityPolicy: "default-src 'self'",
+	}
+	h := chain(ws.handler(notFoundHandler), ws.addSecurityHeaders)
+
+	tests := []struct {
+		path    string
+		wantCSP string
+	}{
+		{path: "/api/v1/contacts", wantCSP: "default-src 'none'"},
+		{path: "/swagger/index.html", wantCSP: "default-src 'self'"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+
+		// act
+		h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+
+		// assert
+		for header, want := range map[string]string{
+			"Strict-Transport-Security": "max-age=3600; includeSubDomains",
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "DENY",
+			"Referrer-Policy":           "no-referrer",
+			"Content-Security-Policy":   tt.wantCSP,
+		} {
+			if got := rec.Header().Get(header); got != want {
+				t.Errorf("%s %s, want: %q got: %q", tt.path, header, want, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const tracerName = "github.com/vicesoftware/vice-go-boilerplate/cmd/webserver"
+
+// startRequestSpan starts the server span for a request, continuing the caller's trace when the
+// request carries a W3C traceparent header. The returned request carries the span's context;
+// pass r.Context() to database.DB.WithContext so provider calls and queries are nested beneath it.
+func startRequestSpan(r *http.Request) (*http.Request, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	route := routeTemplate(r)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.HTTPRoute(route),
+			semconv.URLPath(r.URL.Path),
+			attribute.String("http.request_id", requestid.FromContext(r.Context())),
+		),
+	)
+
+	// correlate log lines with the trace
+	if span.SpanContext().HasTraceID() {
+		ctx = log.NewContext(ctx, zap.String("trace_id", span.SpanContext().TraceID().String()))
+	}
+
+	return r.WithContext(ctx), span
+}
+
+// endRequestSpan records the outcome of the request. Only server errors mark the span as failed;
+// a 4xx is the client's problem, not ours.
+func endRequestSpan(span trace.Span, status int, err error) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if err != nil {
+		span.RecordError(err)
+	}
+	if status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
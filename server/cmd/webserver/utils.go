@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+)
+
+// Ok writes value to the client in the format negotiated from its Accept header, see formats.
+func Ok(w http.ResponseWriter, value interface{}) error {
+	return Respond(w, http.StatusOK, value)
+}
+
+// Respond writes value to the client with a status other than 200.
+func Respond(w http.ResponseWriter, status int, value interface{}) error {
+	f, b, err := marshalResponse(w, value)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("content-type", f.mediaType)
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+	return err
+}
+
+// responseWriter records the status code and number of bytes written by a handler so they can be
+// logged, and whether the headers have been sent so an error response is never written on top of
+// a response that has already started. It also carries the formats the client accepts.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+	formats     []*format
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends any buffered data to the client, if the underlying ResponseWriter supports it.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// panicReporter forwards recovered panics to an error tracker such as Sentry. It's called
+// synchronously, so implementations should queue the report rather than send it inline.
+type panicReporter interface {
+	ReportPanic(r *http.Request, err error, stack []byte)
+}
+
+// handler adapts a handler returning an error, logging and tracing the request and writing the
+// error to the client. produces lists the media types a handler writes itself rather than through
+// Ok, such as a file download; the request is refused with a 406 only if the client accepts none of
+// them and none of the formats.
+func (ws *webserver) handler(f func(http.ResponseWriter, *http.Request) error, produces ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// start time for time_taken calculation
+		var (
+			start  = time.Now()
+			status int
+			err    error
+		)
+
+		// trace the request; r now carries the span's context
+		r, span := startRequestSpan(r)
+
+		rw := &responseWriter{ResponseWriter: w, formats: acceptableFormats(r.Header.Get("Accept"))}
+
+		// use defer/recover; if the handler panics we can log the details
+		defer func() {
+			if perr := recover(); perr != nil {
+				status, err = ws.recoverPanic(rw, r, perr)
+			}
+
+			entry := newAccessLogEntry(r, ws.clientIP(r), start, status, rw.bytes, err)
+			endRequestSpan(span, status, err)
+			observeHTTPRequest(r, entry.duration, status)
+			ws.accessLog.Log(entry)
+		}()
+
+		// default to application/json; Ok and Respond set the negotiated type
+		w.Header().Set("content-type", "application/json")
+
+		// call the handler, unless there's no format the client will accept the response in
+		if len(rw.formats) == 0 && !acceptsAny(r.Header.Get("Accept"), produces) {
+			err = &notAcceptable{}
+		} else {
+			err = f(rw, r)
+		}
+
+		// determine http status based on the type of error (if any) returned
+		status = httpStatus(err)
+
+		// if an error was returned write it to the client
+		// TODO: for security reasons you may not always want to return to raw error
+		// TODO: to the client
+		if err != nil {
+			writeError(rw, r, status, err)
+		}
+
+		// the status actually sent wins; the handler may have written its own, or started the
+		// response before failing
+		if rw.wroteHeader {
+			status = rw.status
+		}
+
+		// the defer function handles writing log output
+	}
+}
+
+func acceptsAny(accept string, mediaTypes []string) bool {
+	for _, mediaType := range mediaTypes {
+		if accepts(accept, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverPanic logs a panic with its stack trace, reports it to the panic reporter if there is one,
+// and sends the client a 500 if the response hasn't already started. It returns the status that was
+// sent and the panic as an error.
+func (ws *webserver) recoverPanic(w *responseWriter, r *http.Request, perr interface{}) (int, error) {
+	// http.ErrAbortHandler is how a handler deliberately aborts a response; let net/http handle it
+	if perr == http.ErrAbortHandler {
+		panic(perr)
+	}
+
+	// panics are certainly going to be 500's
+	//
+	// panics are NOT used like exceptions in .NET/Java, they are usually
+	// the result of incorrect code like a nil pointer dereference, for example.
+	//
+	// in contrast, unable to connect to a database is a normal fact of life and is
+	// represented by an "error", not a forced unwinding of the stack.
+	//
+	// Go encourages you to handle errors (network interruptions and other facts of life)
+	// and panics are reserved for correctness problems.
+
+	// code that panics usually panics with an error, but not necessarily.
+	// fun fact, in .NET you can "throw" anything, it doesn't need to be a type
+	// that derives from System.Exception.
+	panicErr, ok := perr.(error)
+	if !ok {
+		// %v formats the value in a default format; it's useful when you're
+		// not sure (or don't care) what the type is
+		panicErr = fmt.Errorf("%v", perr)
+	}
+
+	stack := debug.Stack()
+	log.FromContext(r.Context()).Error("panic recovered",
+		zap.Error(panicErr),
+		zap.ByteString("stack", stack),
+	)
+	if ws.panicReporter != nil {
+		ws.panicReporter.ReportPanic(r, panicErr, stack)
+	}
+
+	// if the handler already started the response all we can do is stop; the client sees a
+	// truncated body. otherwise send a generic error, the panic value is for us, not the client.
+	if w.wroteHeader {
+		return w.status, panicErr
+	}
+	writeError(w, r, http.StatusInternalServerError, errors.New("internal server error"))
+	return http.StatusInternalServerError, panicErr
+}
+
+// writeError sends err to the client in the standard error envelope, in the negotiated format if
+// it can represent it and JSON otherwise. If the response has already started it's too late to
+// change it, so nothing is written.
+func writeError(w *responseWriter, r *http.Request, status int, err error) {
+	if w.wroteHeader {
+		return
+	}
+
+	response := errorResponse(r, err)
+	f, b, marshalErr := marshalResponse(w, response)
+	if marshalErr != nil {
+		f = formats[0]
+		b, _ = f.marshal(response)
+	}
+
+	w.Header().Set("content-type", f.mediaType)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// routeVariablePattern matches a route variable with a pattern, e.g. {contactID:[0-9]+}.
+var routeVariablePattern = regexp.MustCompile(`\{([^:{}]+):[^{}]*\}`)
+
+// routeTemplate returns the path template of the mux route that matched the request, e.g.
+// /api/v1/contacts/{contactID}, or "unmatched" when no route did. Variable patterns are left out,
+// so metrics and rate limits are keyed by the path as it's documented.
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return routeVariablePattern.ReplaceAllString(template, "{$1}")
+		}
+	}
+	return unmatchedRoute
+}
+
+// clientIP returns the IP address of the client that made the request, as resolved by the
+// resolveClientIP middleware.
+func (ws *webserver) clientIP(r *http.Request) string {
+	if ip := clientip.FromContext(r.Context()); ip != "" {
+		return ip
+	}
+	return ws.clientIPs.ClientIP(r)
+}
+
+// contactFilter reads the filters shared by the contact list and export endpoints from the query.
+func contactFilter(r *http.Request) database.ContactFilter {
+	query := r.URL.Query()
+	return database.ContactFilter{
+		Name: strings.TrimSpace(query.Get("name")),
+		City: strings.TrimSpace(query.Get("city")),
+		Tag:  strings.TrimSpace(query.Get("tag")),
+	}
+}
+
+func notFoundHandler(_ http.ResponseWriter, _ *http.Request) error {
+	return &notFound{}
+}
+
+func httpStatus(err error) int {
+	if err == nil {
+		return 200
+	}
+	if isInvalidRequest(err) || database.IsInvalidRequest(err) {
+		return 400
+	}
+	if isUnauthorized(err) {
+		return 401
+	}
+	if isNotFound(err) || database.IsNotFound(err) {
+		return 404
+	}
+	if isMethodNotAllowed(err) {
+		return 405
+	}
+	if isNotAcceptable(err) {
+		return 406
+	}
+	if isRequestTooLarge(err) {
+		return 413
+	}
+	if isUnsupportedMediaType(err) {
+		return 415
+	}
+	if isTooManyRequests(err) {
+		return 429
+	}
+	return 500
+}
+
+func isInvalidRequest(err error) bool {
+	_, ok := err.(*invalidRequest)
+	return ok
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(*notFound)
+	return ok
+}
+
+func isMethodNotAllowed(err error) bool {
+	_, ok := err.(*methodNotAllowed)
+	return ok
+}
+
+func isNotAcceptable(err error) bool {
+	_, ok := err.(*notAcceptable)
+	return ok
+}
+
+func isRequestTooLarge(err error) bool {
+	_, ok := err.(*requestTooLarge)
+	return ok
+}
+
+func isUnsupportedMediaType(err error) bool {
+	_, ok := err.(*unsupportedMediaType)
+	return ok
+}
+
+func isUnauthorized(err error) bool {
+	_, ok := err.(*unauthorized)
+	return ok
+}
+
+func isTooManyRequests(err error) bool {
+	_, ok := err.(*tooManyRequests)
+	return ok
+}
+
+func errorResponse(r *http.Request, err error) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error:     err.Error(),
+		RequestID: requestid.FromContext(r.Context()),
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+)
+
+type fakePanicReporter struct {
+	err   error
+	stack []byte
+}
+
+func (f *fakePanicReporter) ReportPanic(_ *http.Request, err error, stack []byte) {
+	f.err = err
+	f.stack = stack
+}
+
+func newTestWebserver() *webserver {
+	return &webserver{accessLog: newAccessLogger(10, accessLogPolicyDrop, nil)}
+}
+
+func TestHandler_PanicReturnsJSONError(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	reporter := &fakePanicReporter{}
+	ws.panicReporter = reporter
+
+	h := chain(ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}), requestID)
+
+	req := httptest.NewRequest("GET", "/ping", nil)
+	req.Header.Set(requestid.Header, "abc")
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, req)
+
+	// assert
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status, want: %d got: %d", http.StatusInternalServerError, rec.Code)
+	}
+	if got := rec.Header().Get("content-type"); got != "application/json" {
+		t.Errorf("content-type, want: application/json got: %q", got)
+	}
+
+	var body models.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "internal server error" {
+		t.Errorf("Error, want: %q got: %q", "internal server error", body.Error)
+	}
+	if body.RequestID != "abc" {
+		t.Errorf("RequestID, want: %q got: %q", "abc", body.RequestID)
+	}
+
+	if reporter.err == nil || reporter.err.Error() != "boom" {
+		t.Errorf("reported error, want: boom got: %v", reporter.err)
+	}
+	if len(reporter.stack) == 0 {
+		t.Errorf("reported stack, want: non-empty got: empty")
+	}
+}
+
+func TestHandler_PanicAfterWriteKeepsResponse(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		_ = Ok(w, models.PingResponse{Message: "pong"})
+		panic(errors.New("boom"))
+	})
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+
+	// assert
+	if rec.Code != http.StatusOK {
+		t.Errorf("status, want: %d got: %d", http.StatusOK, rec.Code)
+	}
+	if got, want := rec.Body.String(), `{"msg":"pong"}`; got != want {
+		t.Errorf("body, want: %s got: %s", want, got)
+	}
+}
+
+func TestHandler_ErrorReturnsJSONEnvelope(t *testing.T) {
+	// arrange
+	ws := newTestWebserver()
+	h := ws.handler(func(w http.ResponseWriter, r *http.Request) error {
+		return &notFound{}
+	})
+	rec := httptest.NewRecorder()
+
+	// act
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/contacts/1", nil))
+
+	// assert
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status, want: %d got: %d", http.StatusNotFound, rec.Code)
+	}
+	if got, want := rec.Body.String(), `{"error":"not found"}`; got != want {
+		t.Errorf("body, want: %s got: %s", want, got)
+	}
+}
+
+func TestRouteTemplate_LeavesOutVariablePatterns(t *testing.T) {
+	// arrange
+	var got string
+	r := mux.NewRouter()
+	r.HandleFunc("/contacts/{contactID:[0-9]+}/addresses/{addressID}", func(w http.ResponseWriter, r *http.Request) {
+		got = routeTemplate(r)
+	})
+	req := httptest.NewRequest("GET", "/contacts/1/addresses/2", nil)
+
+	// act
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	// assert
+	if want := "/contacts/{contactID}/addresses/{addressID}"; got != want {
+		t.Errorf("routeTemplate, want: %q got: %q", want, got)
+	}
+}
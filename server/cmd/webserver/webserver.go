@@ -0,0 +1,603 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	httpSwagger "github.com/swaggo/http-swagger"
+	_ "github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/docs"
+	"github.com/vicesoftware/vice-go-boilerplate/cmd/webserver/models"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/health"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+type webserver struct {
+	addr string
+	db   database.DB
+
+	// shutdownDelay is how long readiness fails before the server stops accepting connections,
+	// giving load balancers time to notice. shutdownTimeout bounds the wait for in-flight requests.
+	shutdownDelay   time.Duration
+	shutdownTimeout time.Duration
+
+	accessLog     *accessLogger
+	panicReporter panicReporter // optional
+
+	clientIPs  *clientip.Resolver // knows which proxies may report the client IP
+	rateLimits rateLimits
+	cors       corsConfig
+	security   securityHeaders
+
+	maxBodyBytes   int64 // the largest request body accepted, 0 for no limit
+	maxImportBytes int64 // the same for import files
+
+	logLevelToken string // the bearer token /log/level requires; empty disables it
+
+	liveness     *health.Registry
+	readiness    *health.Registry
+	shuttingDown int32 // accessed atomically, 1 once shutdown has started
+}
+
+func (ws *webserver) Start() {
+	ws.registerHealthChecks()
+
+	srv := &http.Server{Addr: ws.addr, Handler: chain(ws.router(), ws.addSecurityHeaders, requestID, ws.resolveClientIP, ws.allowCORS)}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("starting http server", zap.String("addr", ws.addr))
+		errs <- srv.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-errs:
+		log.Fatal("http server failed", zap.Error(err))
+	case sig := <-signals:
+		log.Info("shutdown signal received", zap.String("signal", sig.String()))
+	}
+
+	ws.shutdown(srv)
+}
+
+// shutdown fails readiness, waits for shutdownDelay, then stops accepting connections and waits
+// for in-flight requests before flushing the access log and closing the database.
+func (ws *webserver) shutdown(srv *http.Server) {
+	atomic.StoreInt32(&ws.shuttingDown, 1)
+	time.Sleep(ws.shutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ws.shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error("http server shutdown failed", zap.Error(err))
+	}
+
+	// flush the access log entries queued so far; if the shutdown timed out, handlers still running
+	// can't be waited for, and the entries they log later are dropped
+	ws.accessLog.Close()
+	if err := ws.db.Close(); err != nil {
+		log.Error("database close failed", zap.Error(err))
+	}
+
+	log.Info("shutdown complete")
+}
+
+func (ws *webserver) router() *mux.Router {
+	r := mux.NewRouter()
+	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+
+	r.NotFoundHandler = ws.handler(notFoundHandler)
+
+	// handle /ping for convenience. we'll also handle /api/v1/ping with the same function.
+	r.HandleFunc("/ping", ws.handler(ws.handlePing)).Methods("GET")
+
+	// orchestrator probes; /ping only proves the process is up, these report why it isn't ready.
+	r.HandleFunc("/healthz", ws.handler(ws.handleHealthz)).Methods("GET")
+	r.HandleFunc("/readyz", ws.handler(ws.handleReadyz)).Methods("GET")
+	r.Handle("/metrics", ws.metricsHandler()).Methods("GET")
+	r.Handle("/log/level", ws.rateLimit(ws.handler(ws.handleLogLevel))).Methods("GET", "PUT")
+
+	apiv1 := r.PathPrefix("/api/v1").Subrouter()
+	apiv1.Use(ws.rateLimit)
+
+	apiv1.HandleFunc("/ping", ws.handler(ws.handlePing)).Methods("GET")
+
+	apiv1.HandleFunc("/batch", ws.handler(ws.handleBatch)).Methods("POST")
+
+	apiv1.HandleFunc("/audit", ws.handler(ws.handleGetAuditEntries)).Methods("GET")
+
+	// contact IDs are numeric, so /contacts/export and the other named paths only match their own routes
+	apiv1.HandleFunc("/contacts", ws.handler(ws.handleGetContacts)).Methods("GET")
+	apiv1.HandleFunc("/contacts/export", ws.handler(ws.handleExportContacts, exportMediaTypes()...)).Methods("GET")
+	apiv1.HandleFunc("/contacts/near", ws.handler(ws.handleGetContactsNear)).Methods("GET")
+	apiv1.HandleFunc("/contacts/duplicates", ws.handler(ws.handleGetDuplicateContacts)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}", ws.handler(ws.handleGetContact)).Methods("GET")
+	apiv1.HandleFunc("/contacts", ws.handler(ws.handlePostContact)).Methods("POST")
+	apiv1.HandleFunc("/contacts/import", ws.handler(ws.handleImportContacts)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}", ws.handler(ws.handlePutContact)).Methods("PUT")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}", ws.handler(ws.handleDeleteContact)).Methods("DELETE")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/merge", ws.handler(ws.handleMergeContact)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/history", ws.handler(ws.handleGetContactHistory)).Methods("GET")
+
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/addresses", ws.handler(ws.handleGetContactAddresses)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/addresses/{addressID}", ws.handler(ws.handleGetContactAddress)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/addresses", ws.handler(ws.handlePostContactAddresses)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/addresses/{addressID}", ws.handler(ws.handlePutContactAddress)).Methods("PUT")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/addresses/{addressID}", ws.handler(ws.handleDeleteContactAddress)).Methods("DELETE")
+
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/emails", ws.handler(ws.handleGetContactEmails)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/emails/{emailID}", ws.handler(ws.handleGetContactEmail)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/emails", ws.handler(ws.handlePostContactEmails)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/emails/{emailID}", ws.handler(ws.handlePutContactEmail)).Methods("PUT")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/emails/{emailID}", ws.handler(ws.handleDeleteContactEmail)).Methods("DELETE")
+
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/phones", ws.handler(ws.handleGetContactPhones)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/phones/{phoneID}", ws.handler(ws.handleGetContactPhone)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/phones", ws.handler(ws.handlePostContactPhones)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/phones/{phoneID}", ws.handler(ws.handlePutContactPhone)).Methods("PUT")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/phones/{phoneID}", ws.handler(ws.handleDeleteContactPhone)).Methods("DELETE")
+
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/tags", ws.handler(ws.handleGetContactTags)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/tags/{tagID}", ws.handler(ws.handleGetContactTag)).Methods("GET")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/tags", ws.handler(ws.handlePostContactTags)).Methods("POST")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/tags/{tagID}", ws.handler(ws.handlePutContactTag)).Methods("PUT")
+	apiv1.HandleFunc("/contacts/{contactID:[0-9]+}/tags/{tagID}", ws.handler(ws.handleDeleteContactTag)).Methods("DELETE")
+
+	// the Allow header is computed from the routes above, so this must come after them
+	routes, err := collectRouteMethods(r)
+	if err != nil {
+		log.Fatal("collecting route methods failed", zap.Error(err))
+	}
+	r.MethodNotAllowedHandler = ws.handler(methodNotAllowedHandler(routes))
+
+	return r
+}
+
+// @Summary Ping server
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.PingResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /ping [get]
+func (ws *webserver) handlePing(w http.ResponseWriter, r *http.Request) error {
+	return Ok(w, models.PingResponse{Message: "pong"})
+}
+
+// @Summary Get all contacts
+// @Param name query string false "Only contacts whose name contains this, ignoring case"
+// @Param city query string false "Only contacts with an address in this city, ignoring case"
+// @Param tag query string false "Only contacts with this tag, ignoring case"
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.ContactResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Router /contacts [get]
+func (ws *webserver) handleGetContacts(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get all contacts matching the filters
+	contacts, err := db.Contacts.Find(contactFilter(r))
+	if err != nil {
+		return err
+	}
+
+	// get all addresses for each contact
+	response := make([]models.ContactResponse, 0)
+	for _, contact := range contacts {
+		addresses, err := db.Addresses.GetAllByContactID(contact.ID)
+		if err != nil {
+			return err
+		}
+		response = append(response, models.MapContactResponse(contact, addresses))
+	}
+
+	return Ok(w, response)
+}
+
+// @Summary Get a contact
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.ContactResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID} [get]
+func (ws *webserver) handleGetContact(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get contact
+	contact, err := db.Contacts.Get(id)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response, err := contactDetailsResponse(db, contact)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, response)
+}
+
+// contactDetailsResponse returns the contact with its addresses, emails, phones and tags.
+func contactDetailsResponse(db database.DB, contact database.Contact) (models.ContactResponse, error) {
+	addresses, err := db.Addresses.GetAllByContactID(contact.ID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	emails, err := db.Emails.GetAllByContactID(contact.ID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	phones, err := db.Phones.GetAllByContactID(contact.ID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+	tags, err := db.Tags.GetAllByContactID(contact.ID)
+	if err != nil {
+		return models.ContactResponse{}, err
+	}
+
+	response := models.MapContactResponse(contact, addresses)
+	response.Emails = models.MapContactEmails(emails)
+	response.Phones = models.MapContactPhones(phones)
+	response.Tags = models.MapContactTags(tags)
+	return response, nil
+}
+
+// @Summary Create a contact
+// @Description Creates the contact and its addresses, if any, in one transaction. Returns the contact
+// @Description with its addresses, emails, phones and tags.
+// @Param contact body models.ContactRequest true "Create contact"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.ContactResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Router /contacts [post]
+func (ws *webserver) handlePostContact(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// create var ready to hold decoded json from body
+	var request models.ContactRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// create contact and its addresses
+	create := models.MapCreateContactRequest(request)
+	contact, _, err := db.Contacts.CreateWithAddresses(create, models.MapCreateAddressRequests(0, request.Addresses))
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response, err := contactDetailsResponse(db, contact)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, response)
+}
+
+// @Summary Update a contact
+// @Description Replaces the contact's fields; company, title and notes are cleared if left out. Updates the contact and, if the body has addresses, replaces all of its addresses with them
+// @Description in one transaction. Replaced addresses get new IDs. Returns the contact with its addresses,
+// @Description emails, phones and tags.
+// @Param contact body models.ContactRequest true "Update contact"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.ContactResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID} [put]
+func (ws *webserver) handlePutContact(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded json from body
+	var request models.ContactRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// update contact, replacing its addresses if the request has them
+	update := models.MapUpdateContactRequest(id, request)
+	contact, _, err := db.Contacts.UpdateWithAddresses(update, models.MapCreateAddressRequests(id, request.Addresses))
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response, err := contactDetailsResponse(db, contact)
+	if err != nil {
+		return err
+	}
+
+	return Ok(w, response)
+}
+
+// @Summary Delete a contact
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {string} string "{}"
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID} [delete]
+func (ws *webserver) handleDeleteContact(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// delete contact
+	if err = db.Contacts.Delete(id); err != nil {
+		return err
+	}
+
+	// struct{}{} is an empty object, returns "{}" to the client
+	return Ok(w, struct{}{})
+}
+
+// @Summary Get all of a contact's addresses
+// @Produce json,application/xml,text/csv,application/msgpack
+// @Success 200 {array} models.AddressResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/addresses [get]
+func (ws *webserver) handleGetContactAddresses(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get contact addresses
+	addresses, err := db.Addresses.GetAllByContactID(contactID)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactAddresses(addresses)
+
+	return Ok(w, response)
+}
+
+// @Summary Get a contact address
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.AddressResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param addressID path int true "Address ID"
+// @Router /contacts/{contactID}/addresses/{addressID} [get]
+func (ws *webserver) handleGetContactAddress(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	addressID, err := strconv.Atoi(vars["addressID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get address by ID
+	address, err := db.Addresses.Get(addressID)
+	if err != nil {
+		return err
+	}
+	// ensure address belongs to contact
+	if address.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create response
+	response := models.MapContactAddress(address)
+
+	return Ok(w, response)
+}
+
+// @Summary Create a contact address
+// @Param address body models.AddressRequest true "Create address"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.AddressResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Router /contacts/{contactID}/addresses [post]
+func (ws *webserver) handlePostContactAddresses(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// create var ready to hold decoded json from body
+	var request models.AddressRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// create contact
+	create := models.MapCreateAddressRequest(contactID, request)
+	address, err := db.Addresses.Create(create)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactAddress(address)
+
+	return Ok(w, response)
+}
+
+// @Summary Update a contact address
+// @Param address body models.AddressRequest true "Update address"
+// @Accept json,application/xml,text/csv,application/msgpack
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {object} models.AddressResponse
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 415 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param addressID path int true "Address ID"
+// @Router /contacts/{contactID}/addresses/{addressID} [put]
+func (ws *webserver) handlePutContactAddress(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	addressID, err := strconv.Atoi(vars["addressID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get address
+	address, err := db.Addresses.Get(addressID)
+	if err != nil {
+		return err
+	}
+	// ensure address belongs to contact
+	if address.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// create var ready to hold decoded json from body
+	var request models.AddressRequest
+
+	// decode body
+	if err := ws.decode(w, r, &request); err != nil {
+		return err
+	}
+
+	// update address
+	update := models.MapUpdateAddressRequest(contactID, addressID, request)
+	newAddress, err := db.Addresses.Update(update)
+	if err != nil {
+		return err
+	}
+
+	// create response
+	response := models.MapContactAddress(newAddress)
+
+	return Ok(w, response)
+}
+
+// @Summary Delete a contact address
+// @Description If it was the contact's primary address, the contact's oldest remaining address becomes primary.
+// @Produce json,application/xml,application/msgpack
+// @Success 200 {string} string "{}"
+// @Failure 406 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Param contactID path int true "Contact ID"
+// @Param addressID path int true "Address ID"
+// @Router /contacts/{contactID}/addresses/{addressID} [delete]
+func (ws *webserver) handleDeleteContactAddress(w http.ResponseWriter, r *http.Request) error {
+	// run queries under the request's trace
+	db := ws.db.WithContext(r.Context())
+
+	// get url params
+	vars := mux.Vars(r)
+	contactID, err := strconv.Atoi(vars["contactID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+	addressID, err := strconv.Atoi(vars["addressID"])
+	if err != nil {
+		return &invalidRequest{}
+	}
+
+	// get address
+	address, err := db.Addresses.Get(addressID)
+	if err != nil {
+		return err
+	}
+	// ensure address belongs to contact
+	if address.ContactID != contactID {
+		return &notFound{}
+	}
+
+	// delete address
+	if err = db.Addresses.Delete(addressID); err != nil {
+		return err
+	}
+
+	// struct{}{} is an empty object, returns "{}" to the client
+	return Ok(w, struct{}{})
+}
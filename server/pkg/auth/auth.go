@@ -0,0 +1,19 @@
+package auth
+
+import "context"
+
+// the API doesn't authenticate requests yet. whatever middleware ends up verifying credentials
+// should call NewContext so rate limiting and auditing can tell users apart.
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the authenticated user's ID.
+func NewContext(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserID returns the authenticated user's ID, or "" if the request isn't authenticated.
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
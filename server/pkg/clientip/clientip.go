@@ -0,0 +1,159 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Resolver determines the IP address of the client that made a request. Forwarding headers are
+// only believed when the connection comes from a trusted proxy, otherwise any client could claim
+// any IP; they're read from the right, skipping the trusted proxies that appended to them, since
+// the entries further left were supplied by the client.
+//
+// The headers are consulted in order: Forwarded (RFC 7239), X-Real-IP, then X-Forwarded-For. A
+// header that doesn't yield an IP is ignored in favor of the next.
+type Resolver struct {
+	trusted []*net.IPNet
+}
+
+// NewResolver returns a Resolver trusting the given proxies, each a CIDR such as 10.0.0.0/8 or a
+// single IP.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q isn't an IP or CIDR", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			res.trusted = append(res.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q isn't an IP or CIDR", proxy)
+		}
+		res.trusted = append(res.trusted, network)
+	}
+	return res, nil
+}
+
+// ClientIP returns the client's IP address. A nil Resolver trusts no proxies.
+func (res *Resolver) ClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !res.isTrusted(ip) {
+		return ip
+	}
+
+	if forwarded := r.Header.Values("Forwarded"); len(forwarded) > 0 {
+		if client := res.walk(forwardedFor(forwarded)); client != "" {
+			return client
+		}
+	}
+	if realIP := stripPort(strings.TrimSpace(r.Header.Get("X-Real-IP"))); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+		if client := res.walk(splitList(forwardedFor)); client != "" {
+			return client
+		}
+	}
+	return ip
+}
+
+// walk returns the rightmost hop that isn't a trusted proxy. A hop that isn't an IP, such as
+// RFC 7239's "unknown" or an obfuscated identifier, can't be checked, so the hop after it is used,
+// or "" if it's the last hop, leaving the next header to be consulted.
+func (res *Resolver) walk(hops []string) string {
+	ip := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := stripPort(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !res.isTrusted(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+func (res *Resolver) isTrusted(ip string) bool {
+	if res == nil {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range res.trusted {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the for= node of each element of the Forwarded headers, e.g.
+//
+//	Forwarded: for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8:cafe::17]:4711"
+//
+// yields 192.0.2.60 and [2001:db8:cafe::17]:4711. Elements without a for= parameter yield "".
+func forwardedFor(headers []string) []string {
+	var nodes []string
+	for _, element := range splitList(headers) {
+		node := ""
+		for _, pair := range strings.Split(element, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(name, "for") {
+				node = strings.Trim(value, `"`)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// splitList splits comma separated header values, which may be repeated across several headers.
+func splitList(headers []string) []string {
+	var values []string
+	for _, header := range headers {
+		for _, value := range strings.Split(header, ",") {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values
+}
+
+// stripPort removes the port from 192.0.2.60:4711 and the brackets and port from [2001:db8::1]:4711.
+func stripPort(node string) string {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return strings.Trim(node, "[]")
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the resolved client IP.
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client IP carried by ctx, or "" if there isn't one.
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
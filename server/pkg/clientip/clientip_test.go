@@ -0,0 +1,129 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	res, err := NewResolver([]string{"10.0.0.0/8", "127.0.0.1", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer can't spoof",
+			remoteAddr: "203.0.113.7:5000",
+			headers:    map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "1.2.3.4", "Forwarded": "for=1.2.3.4"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "X-Real-IP from trusted proxy",
+			remoteAddr: "127.0.0.1:5000",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "X-Forwarded-For skips trusted hops",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 10.0.0.2"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "Forwarded takes precedence",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"Forwarded": `for=1.2.3.4, for="[2001:db8:cafe::17]:4711";proto=https, for=10.0.0.2`, "X-Real-IP": "1.2.3.4"},
+			want:       "2001:db8:cafe::17",
+		},
+		{
+			name:       "Forwarded unknown node falls back to the proxy",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"Forwarded": "for=unknown"},
+			want:       "10.1.2.3",
+		},
+		{
+			name:       "Forwarded unknown node falls through to X-Real-IP",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"Forwarded": "for=unknown", "X-Real-IP": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "invalid X-Real-IP falls through to X-Forwarded-For",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"X-Real-IP": "not-an-ip", "X-Forwarded-For": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "invalid X-Forwarded-For falls back to the proxy",
+			remoteAddr: "10.1.2.3:5000",
+			headers:    map[string]string{"X-Real-IP": "<script>", "X-Forwarded-For": "garbage"},
+			want:       "10.1.2.3",
+		},
+		{
+			name:       "X-Real-IP with port",
+			remoteAddr: "127.0.0.1:5000",
+			headers:    map[string]string{"X-Real-IP": "[2001:db8::1]:4711"},
+			want:       "2001:db8::1",
+		},
+		{
+			name:       "IPv6 loopback proxy",
+			remoteAddr: "[::1]:5000",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "trusted proxy without headers",
+			remoteAddr: "127.0.0.1:5000",
+			want:       "127.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		for name, value := range tt.headers {
+			req.Header.Set(name, value)
+		}
+
+		// act
+		got := res.ClientIP(req)
+
+		// assert
+		if got != tt.want {
+			t.Errorf("%s: ClientIP, want: %q got: %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNewResolver_RejectsInvalidProxies(t *testing.T) {
+	// act
+	_, err := NewResolver([]string{"10.0.0.0/33"})
+
+	// assert
+	if err == nil {
+		t.Errorf("NewResolver error, want: error got: nil")
+	}
+}
+
+func TestResolver_NilTrustsNothing(t *testing.T) {
+	// arrange
+	var res *Resolver
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	req.Header.Set("X-Real-IP", "1.2.3.4")
+
+	// act
+	got := res.ClientIP(req)
+
+	// assert
+	if got != "127.0.0.1" {
+		t.Errorf("ClientIP, want: %q got: %q", "127.0.0.1", got)
+	}
+}
@@ -0,0 +1,150 @@
+package contactimport
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// Record is one contact read from an import file. Row is the line a CSV record starts on, or the
+// position of a card in a vCard file, so problems can be reported against the source. Err is set
+// if the record couldn't be read; such records are still returned so they can be reported.
+type Record struct {
+	Row       int
+	Contact   database.Contact
+	Addresses []database.Address
+	Err       error
+}
+
+// IsEmpty reports whether the record has no data at all, e.g. a blank spreadsheet row.
+func (r Record) IsEmpty() bool {
+	c := r.Contact
+	return r.Err == nil && c.FirstName == "" && c.LastName == "" && c.Company == nil && c.Title == nil && c.Notes == nil && len(r.Addresses) == 0
+}
+
+// Key identifies records with the same contents, ignoring case, so duplicates within a file can
+// be skipped.
+func (r Record) Key() string {
+	parts := []string{r.Contact.FirstName, r.Contact.LastName}
+	for _, optional := range []*string{r.Contact.Company, r.Contact.Title, r.Contact.Notes} {
+		value := ""
+		if optional != nil {
+			value = *optional
+		}
+		parts = append(parts, value)
+	}
+	for _, a := range r.Addresses {
+		line2 := ""
+		if a.Line2 != nil {
+			line2 = *a.Line2
+		}
+		parts = append(parts, a.Line1, line2, a.City, a.StateProvince, a.PostalCode, a.Country)
+	}
+	return strings.ToLower(strings.Join(parts, "\x00"))
+}
+
+// maximum lengths of the columns in the contacts and addresses tables
+const (
+	maxNameLength          = 100
+	maxCompanyLength       = 100
+	maxTitleLength         = 100
+	maxLineLength          = 100
+	maxCityLength          = 50
+	maxStateProvinceLength = 50
+	maxPostalCodeLength    = 50
+)
+
+// Validate checks the record can be stored: it needs a name, each address needs a street, city
+// and postal code valid for its country, and nothing may be longer than its column.
+func Validate(r Record) error {
+	if r.Err != nil {
+		return r.Err
+	}
+
+	c := r.Contact
+	if c.FirstName == "" && c.LastName == "" {
+		return fmt.Errorf("firstName or lastName is required")
+	}
+	if err := checkLength("firstName", c.FirstName, maxNameLength); err != nil {
+		return err
+	}
+	if err := checkLength("lastName", c.LastName, maxNameLength); err != nil {
+		return err
+	}
+	if c.Company != nil {
+		if err := checkLength("company", *c.Company, maxCompanyLength); err != nil {
+			return err
+		}
+	}
+	if c.Title != nil {
+		if err := checkLength("title", *c.Title, maxTitleLength); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range r.Addresses {
+		switch {
+		case a.Line1 == "":
+			return fmt.Errorf("address line1 is required")
+		case a.City == "":
+			return fmt.Errorf("address city is required")
+		case a.PostalCode == "":
+			return fmt.Errorf("address postalCode is required")
+		}
+
+		line2 := ""
+		if a.Line2 != nil {
+			line2 = *a.Line2
+		}
+		for _, check := range []struct {
+			field string
+			value string
+			max   int
+		}{
+			{"line1", a.Line1, maxLineLength},
+			{"line2", line2, maxLineLength},
+			{"city", a.City, maxCityLength},
+			{"stateProvince", a.StateProvince, maxStateProvinceLength},
+			{"postalCode", a.PostalCode, maxPostalCodeLength},
+		} {
+			if err := checkLength("address "+check.field, check.value, check.max); err != nil {
+				return err
+			}
+		}
+
+		country := a.Country
+		if country == "" {
+			country = database.DefaultCountry
+		}
+		_, err := postal.Normalize(postal.Address{
+			Line1:         a.Line1,
+			Line2:         line2,
+			City:          a.City,
+			StateProvince: a.StateProvince,
+			PostalCode:    a.PostalCode,
+			Country:       country,
+		})
+		if err != nil {
+			return fmt.Errorf("address %v", err)
+		}
+	}
+	return nil
+}
+
+func checkLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Errorf("%s is longer than %d characters", field, max)
+	}
+	return nil
+}
+
+// optional returns nil for an empty string, for optional columns such as line2.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,274 @@
+package contactimport
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV_WithMapping(t *testing.T) {
+	// arrange
+	file := "\ufeffGiven Name,Surname,Street,City,Zip,Notes\n" +
+		"John,Doe,1 Main St,Springfield,62701,likes cake\n" +
+		"Jane,Roe\n"
+	mapping := map[string]string{"firstName": "Given Name", "lastName": "surname", "line1": "Street", "postalCode": "Zip"}
+
+	// act
+	records, err := ParseCSV(strings.NewReader(file), mapping)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records), want: 2 got: %d", len(records))
+	}
+
+	john := records[0]
+	if john.Row != 2 || john.Contact.FirstName != "John" || john.Contact.LastName != "Doe" {
+		t.Errorf("records[0], want: row 2 John Doe got: row %d %s %s", john.Row, john.Contact.FirstName, john.Contact.LastName)
+	}
+	if len(john.Addresses) != 1 || john.Addresses[0].Line1 != "1 Main St" || john.Addresses[0].PostalCode != "62701" || john.Addresses[0].City != "Springfield" {
+		t.Errorf("records[0].Addresses, want: 1 Main St, Springfield 62701 got: %+v", john.Addresses)
+	}
+	if len(records[1].Addresses) != 0 {
+		t.Errorf("records[1].Addresses, want: none got: %+v", records[1].Addresses)
+	}
+}
+
+func TestParseCSV_CompanyTitleNotes(t *testing.T) {
+	// arrange
+	file := "firstName,lastName,Company,title,notes\n" +
+		"Ada,Lovelace,Analytical Engines Ltd.,Countess,\"first programmer,\nmathematician\"\n" +
+		"Alan,Turing,,,\n"
+
+	// act
+	records, err := ParseCSV(strings.NewReader(file), nil)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records), want: 2 got: %d", len(records))
+	}
+	ada := records[0].Contact
+	if ada.Company == nil || *ada.Company != "Analytical Engines Ltd." || ada.Title == nil || *ada.Title != "Countess" ||
+		ada.Notes == nil || *ada.Notes != "first programmer,\nmathematician" {
+		t.Errorf("records[0], want: company, title and notes got: %v %v %v", ada.Company, ada.Title, ada.Notes)
+	}
+	alan := records[1].Contact
+	if alan.Company != nil || alan.Title != nil || alan.Notes != nil {
+		t.Errorf("records[1], want: no company, title or notes got: %v %v %v", alan.Company, alan.Title, alan.Notes)
+	}
+}
+
+func TestParseCSV_GroupsRowsByID(t *testing.T) {
+	// arrange
+	file := "id,firstName,lastName,line1,city,postalCode\n" +
+		"1,John,Doe,1 Main St,Springfield,62701\n" +
+		"2,Jane,Roe,,,\n" +
+		"1,John,Doe,2 Main St,Springfield,62701\n" +
+		"2,Janet,Roe,3 Main St,Springfield,62701\n"
+
+	// act
+	records, err := ParseCSV(strings.NewReader(file), nil)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("len(records), want: 3 got: %d", len(records))
+	}
+	john := records[0]
+	if john.Row != 2 || len(john.Addresses) != 2 || john.Addresses[1].Line1 != "2 Main St" {
+		t.Errorf("records[0], want: row 2 with both addresses got: row %d %+v", john.Row, john.Addresses)
+	}
+	if records[1].Err != nil || len(records[1].Addresses) != 0 {
+		t.Errorf("records[1], want: Jane without addresses got: %+v", records[1])
+	}
+	if records[2].Row != 5 || records[2].Err == nil {
+		t.Errorf("records[2], want: row 5 with an error got: row %d %v", records[2].Row, records[2].Err)
+	}
+}
+
+func TestParseCSV_IsPrimary(t *testing.T) {
+	// arrange
+	file := "id,firstName,line1,city,postalCode,isPrimary\n" +
+		"1,John,1 Main St,Springfield,62701,false\n" +
+		"1,John,2 Main St,Springfield,62701,true\n" +
+		"2,Jane,3 Main St,Springfield,62701,\n" +
+		"2,Jane,4 Main St,Springfield,62701,yes\n"
+
+	// act
+	records, err := ParseCSV(strings.NewReader(file), nil)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records), want: 2 got: %d", len(records))
+	}
+	john := records[0]
+	if john.Err != nil || len(john.Addresses) != 2 || john.Addresses[0].IsPrimary || !john.Addresses[1].IsPrimary {
+		t.Errorf("records[0], want: the second address primary got: %v %+v", john.Err, john.Addresses)
+	}
+	if records[1].Err == nil {
+		t.Errorf("records[1].Err, want: isPrimary isn't true or false got: nil")
+	}
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		mapping map[string]string
+	}{
+		{name: "empty", file: ""},
+		{name: "no name column", file: "city\nSpringfield\n"},
+		{name: "unknown field", file: "firstName\nJohn\n", mapping: map[string]string{"nickname": "Nick"}},
+		{name: "mapped column missing", file: "firstName\nJohn\n", mapping: map[string]string{"lastName": "Surname"}},
+	}
+
+	for _, tt := range tests {
+		// act
+		_, err := ParseCSV(strings.NewReader(tt.file), tt.mapping)
+
+		// assert
+		if err == nil {
+			t.Errorf("%s: error, want: error got: nil", tt.name)
+		}
+	}
+}
+
+func TestParseVCard(t *testing.T) {
+	// arrange
+	file := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"FN:John Doe",
+		"N:Doe;John;;;",
+		`item1.ADR;TYPE="home,pref";LABEL="1 Main St:Springfield":;Apt 2;1 Main St\, Rear;Spring`,
+		" field;IL;62701;USA",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:3.0",
+		"FN:Prince",
+		"END:VCARD",
+		"BEGIN:VCARD",
+		"VERSION:2.1",
+		"N:Old;Card",
+		"END:VCARD",
+	}, "\r\n")
+
+	// act
+	records, err := ParseVCard(strings.NewReader(file))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("len(records), want: 3 got: %d", len(records))
+	}
+
+	john := records[0]
+	if john.Contact.FirstName != "John" || john.Contact.LastName != "Doe" {
+		t.Errorf("records[0] name, want: John Doe got: %s %s", john.Contact.FirstName, john.Contact.LastName)
+	}
+	if len(john.Addresses) != 1 {
+		t.Fatalf("len(records[0].Addresses), want: 1 got: %d", len(john.Addresses))
+	}
+	address := john.Addresses[0]
+	if address.Line1 != "1 Main St, Rear" || address.Line2 == nil || *address.Line2 != "Apt 2" ||
+		address.City != "Springfield" || address.StateProvince != "IL" || address.PostalCode != "62701" {
+		t.Errorf("records[0].Addresses[0], want: 1 Main St, Rear / Apt 2 / Springfield IL 62701 got: %+v", address)
+	}
+
+	if records[1].Contact.FirstName != "Prince" || records[1].Row != 2 {
+		t.Errorf("records[1], want: row 2 Prince got: row %d %q", records[1].Row, records[1].Contact.FirstName)
+	}
+	if records[2].Err == nil {
+		t.Errorf("records[2].Err, want: unsupported version got: nil")
+	}
+}
+
+func TestParseVCard_OrgTitleNote(t *testing.T) {
+	// arrange
+	file := strings.Join([]string{
+		"BEGIN:VCARD",
+		"VERSION:4.0",
+		"N:Lovelace;Ada;;;",
+		`ORG:Analytical Engines\, Ltd.;Research`,
+		"TITLE:Countess",
+		`NOTE:first programmer\nmathematician`,
+		"END:VCARD",
+	}, "\r\n")
+
+	// act
+	records, err := ParseVCard(strings.NewReader(file))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	ada := records[0].Contact
+	if ada.Company == nil || *ada.Company != "Analytical Engines, Ltd., Research" {
+		t.Errorf("company, want: Analytical Engines, Ltd., Research got: %v", ada.Company)
+	}
+	if ada.Title == nil || *ada.Title != "Countess" {
+		t.Errorf("title, want: Countess got: %v", ada.Title)
+	}
+	if ada.Notes == nil || *ada.Notes != "first programmer\nmathematician" {
+		t.Errorf("notes, want: first programmer\\nmathematician got: %v", ada.Notes)
+	}
+}
+
+func TestParseVCard_Malformed(t *testing.T) {
+	for _, file := range []string{
+		"",
+		"BEGIN:VCARD\nVERSION:4.0\n",
+		"VERSION:4.0\n",
+	} {
+		// act
+		_, err := ParseVCard(strings.NewReader(file))
+
+		// assert
+		if err == nil {
+			t.Errorf("ParseVCard(%q) error, want: error got: nil", file)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		wantErr bool
+	}{
+		{name: "valid", file: "firstName,line1,city,stateProvince,postalCode\nJohn,1 Main St,Springfield,IL,62701\n"},
+		{name: "country", file: "firstName,line1,city,postalCode,country\nJohn,10 Downing St,London,sw1a2aa,United Kingdom\n"},
+		{name: "invalid state", file: "firstName,line1,city,stateProvince,postalCode\nJohn,1 Main St,Springfield,Ontario,62701\n", wantErr: true},
+		{name: "invalid postal code", file: "firstName,line1,city,stateProvince,postalCode,country\nJohn,1 Main St,Toronto,ON,62701,CA\n", wantErr: true},
+		{name: "no name", file: "firstName,lastName,city\n,,Springfield\n", wantErr: true},
+		{name: "incomplete address", file: "firstName,city\nJohn,Springfield\n", wantErr: true},
+		{name: "name too long", file: "firstName\n" + strings.Repeat("a", 101) + "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		// arrange
+		records, err := ParseCSV(strings.NewReader(tt.file), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// act
+		err = Validate(records[0])
+
+		// assert
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate error, want: %v got: %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
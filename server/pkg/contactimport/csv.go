@@ -0,0 +1,155 @@
+package contactimport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+)
+
+// Fields are the contact and address fields a CSV column can be mapped to. id only groups rows
+// into contacts; it isn't imported. isPrimary is true or false; left empty it's false.
+var Fields = []string{"id", "firstName", "lastName", "company", "title", "notes", "line1", "line2", "city", "stateProvince", "postalCode", "country", "isPrimary"}
+
+// ParseCSV reads a CSV file with a header row. mapping maps fields to the header of the column
+// holding them, e.g. "firstName": "Given Name"; fields it doesn't mention are read from a column
+// named after the field. Headers are matched ignoring case, and unmapped columns are ignored.
+// A record gets an address if any address column in it is filled in. Rows with the same id are one
+// contact with an address on each, the way the export endpoint writes them; a row repeating an id
+// for a different contact is returned with Err set.
+func ParseCSV(r io.Reader, mapping map[string]string) ([]Record, error) {
+	for field := range mapping {
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q in column mapping", field)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // spreadsheets often leave trailing cells off
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("CSV has no header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Excel starts UTF-8 files with a byte order mark
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	index := make(map[string]int)
+	for _, field := range Fields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		i, ok := columns[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if _, mapped := mapping[field]; mapped {
+				return nil, fmt.Errorf("column %q mapped to %s isn't in the header", name, field)
+			}
+			continue
+		}
+		index[field] = i
+	}
+	if _, ok := index["firstName"]; !ok {
+		if _, ok := index["lastName"]; !ok {
+			return nil, fmt.Errorf("CSV has no firstName or lastName column")
+		}
+	}
+
+	var records []Record
+	byID := make(map[string]int) // the index in records of the record with each id
+	for {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			// a malformed record spoils everything after it too
+			return nil, err
+		}
+		row, _ := reader.FieldPos(0)
+
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(cells) {
+				return ""
+			}
+			return strings.TrimSpace(cells[i])
+		}
+
+		record := Record{
+			Row: row,
+			Contact: database.Contact{
+				FirstName: value("firstName"),
+				LastName:  value("lastName"),
+				Company:   optional(value("company")),
+				Title:     optional(value("title")),
+				Notes:     optional(value("notes")),
+			},
+		}
+
+		address := database.Address{
+			Line1:         value("line1"),
+			Line2:         optional(value("line2")),
+			City:          value("city"),
+			StateProvince: value("stateProvince"),
+			PostalCode:    value("postalCode"),
+			Country:       value("country"),
+		}
+		if primary := value("isPrimary"); primary != "" {
+			if address.IsPrimary, err = strconv.ParseBool(primary); err != nil {
+				record.Err = fmt.Errorf("isPrimary %q isn't true or false", primary)
+			}
+		}
+		if address.Line1 != "" || address.Line2 != nil || address.City != "" || address.StateProvince != "" || address.PostalCode != "" {
+			record.Addresses = append(record.Addresses, address)
+		}
+
+		if id := value("id"); id != "" {
+			if i, ok := byID[id]; ok {
+				if first := &records[i]; sameContact(first.Contact, record.Contact) {
+					first.Addresses = append(first.Addresses, record.Addresses...)
+					// the contact is imported or rejected as a whole, so a bad row rejects it
+					if record.Err != nil && first.Err == nil {
+						first.Err = fmt.Errorf("row %d: %v", row, record.Err)
+					}
+					continue
+				}
+				record.Err = fmt.Errorf("id %s is used by row %d for a different contact", id, records[i].Row)
+			} else {
+				byID[id] = len(records)
+			}
+		}
+
+		records = append(records, record)
+	}
+}
+
+// sameContact reports whether rows with the same id hold the same contact.
+func sameContact(a, b database.Contact) bool {
+	same := func(x, y *string) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return a.FirstName == b.FirstName && a.LastName == b.LastName &&
+		same(a.Company, b.Company) && same(a.Title, b.Title) && same(a.Notes, b.Notes)
+}
+
+func isField(field string) bool {
+	for _, f := range Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package contactimport
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// ParseVCard reads vCard 3.0 and 4.0 cards (RFC 2426 and RFC 6350). The name is taken from N, or
+// from FN if N is missing, ORG, TITLE and NOTE give the company, title and notes, and each ADR
+// becomes an address. Other properties are ignored. A card
+// that can't be read is returned with Err set; Row is its position in the file.
+func ParseVCard(r io.Reader) ([]Record, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		records []Record
+		card    *vcard
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, err := parseProperty(line)
+		if err != nil {
+			if card != nil && card.err == nil {
+				card.err = err
+			}
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			if card != nil {
+				return nil, fmt.Errorf("card %d has no END:VCARD", len(records)+1)
+			}
+			card = &vcard{}
+		case name == "END" && strings.EqualFold(value, "VCARD"):
+			if card == nil {
+				return nil, fmt.Errorf("END:VCARD without BEGIN:VCARD after card %d", len(records))
+			}
+			records = append(records, card.record(len(records)+1))
+			card = nil
+		case card == nil:
+			return nil, fmt.Errorf("%s property outside of a card", name)
+		default:
+			card.set(name, value)
+		}
+	}
+
+	if card != nil {
+		return nil, fmt.Errorf("card %d has no END:VCARD", len(records)+1)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file contains no vCards")
+	}
+	return records, nil
+}
+
+type vcard struct {
+	version   string
+	n         []string
+	fn        string
+	org       string
+	title     string
+	note      string
+	addresses []database.Address
+	err       error
+}
+
+func (c *vcard) set(name, value string) {
+	switch name {
+	case "VERSION":
+		c.version = value
+	case "N":
+		c.n = splitComponents(value)
+	case "FN":
+		c.fn = unescape(value)
+	case "ORG":
+		// organization name; then units, from the largest to the smallest
+		var units []string
+		for _, unit := range splitComponents(value) {
+			if unit != "" {
+				units = append(units, unit)
+			}
+		}
+		c.org = strings.Join(units, ", ")
+	case "TITLE":
+		c.title = strings.TrimSpace(unescape(value))
+	case "NOTE":
+		c.note = strings.TrimSpace(unescape(value))
+	case "ADR":
+		// post office box; extended address; street; locality; region; postal code; country
+		adr := splitComponents(value)
+		for len(adr) < 7 {
+			adr = append(adr, "")
+		}
+		c.addresses = append(c.addresses, database.Address{
+			Line1:         strings.Join(strings.Split(adr[2], "\n"), ", "),
+			Line2:         optional(adr[1]),
+			City:          adr[3],
+			StateProvince: adr[4],
+			PostalCode:    adr[5],
+			Country:       country(adr[6]),
+		})
+	}
+}
+
+func (c *vcard) record(row int) Record {
+	record := Record{Row: row, Addresses: c.addresses, Err: c.err}
+	record.Contact.Company = optional(c.org)
+	record.Contact.Title = optional(c.title)
+	record.Contact.Notes = optional(c.note)
+	if record.Err == nil && c.version != "3.0" && c.version != "4.0" {
+		record.Err = fmt.Errorf("unsupported vCard version %q, only 3.0 and 4.0 are supported", c.version)
+	}
+
+	// N is family name; given name; additional names; prefixes; suffixes
+	if len(c.n) >= 2 {
+		record.Contact.LastName = c.n[0]
+		record.Contact.FirstName = c.n[1]
+	} else if len(c.n) == 1 {
+		record.Contact.LastName = c.n[0]
+	}
+
+	// FN is required but free-form; fall back to it by splitting at the last space
+	if record.Contact.FirstName == "" && record.Contact.LastName == "" && c.fn != "" {
+		if i := strings.LastIndex(c.fn, " "); i > 0 {
+			record.Contact.FirstName = strings.TrimSpace(c.fn[:i])
+			record.Contact.LastName = strings.TrimSpace(c.fn[i+1:])
+		} else {
+			record.Contact.FirstName = c.fn
+		}
+	}
+	return record
+}
+
+// unfold joins folded lines: a line starting with a space or tab continues the previous one.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line, [group.]name[;param=value...]:value, into its upper case
+// name, without the group, and its raw value. Parameters are ignored, but a colon inside a quoted
+// parameter value isn't taken as the end of the name.
+func parseProperty(line string) (string, string, error) {
+	quoted := false
+	for i, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ':' && !quoted:
+			name := strings.SplitN(line[:i], ";", 2)[0]
+			if dot := strings.LastIndex(name, "."); dot >= 0 {
+				name = name[dot+1:]
+			}
+			return strings.ToUpper(strings.TrimSpace(name)), line[i+1:], nil
+		}
+	}
+	return "", "", fmt.Errorf("malformed line %q", line)
+}
+
+// splitComponents splits a structured value at unescaped semicolons and unescapes each component.
+func splitComponents(value string) []string {
+	var (
+		components []string
+		current    strings.Builder
+		escaped    bool
+	)
+	for _, c := range value {
+		switch {
+		case escaped:
+			current.WriteRune('\\')
+			current.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == ';':
+			components = append(components, strings.TrimSpace(unescape(current.String())))
+			current.Reset()
+		default:
+			current.WriteRune(c)
+		}
+	}
+	return append(components, strings.TrimSpace(unescape(current.String())))
+}
+
+// unescape decodes the \\, \;, \, and \n escapes used in text values.
+func unescape(value string) string {
+	var (
+		b       strings.Builder
+		escaped bool
+	)
+	for _, c := range value {
+		switch {
+		case escaped:
+			if c == 'n' || c == 'N' {
+				b.WriteRune('\n')
+			} else {
+				b.WriteRune(c)
+			}
+			escaped = false
+		case c == '\\':
+			escaped = true
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// country returns the ISO 3166-1 code for an ADR country, which is free text and usually a name.
+// Unknown countries are kept so Validate can report them.
+func country(s string) string {
+	if code, ok := postal.CountryCode(s); ok {
+		return code
+	}
+	return s
+}
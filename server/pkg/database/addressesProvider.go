@@ -4,22 +4,59 @@ import "github.com/jinzhu/gorm"
 
 type AddressProvider struct {
 	db     *gorm.DB
-	parent DB
+	parent *DB
 }
 
-func (a AddressProvider) Create(address Address) (Address, error) {
+func (a AddressProvider) Create(address Address) (_ Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.Create")
+	defer func() { endSpan(span, err) }()
+
 	if address.ID != 0 {
 		return Address{}, &invalidRequest{"create address", "id must be 0"}
 	}
-	if db := a.db.Create(&address); db.Error != nil {
-		return Address{}, db.Error
+	if address.Country == "" {
+		address.Country = DefaultCountry
+	}
+	if address, err = normalizeAddress("create address", address); err != nil {
+		return Address{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		if err := lockContacts(tx.db, "create address", address.ContactID); err != nil {
+			return err
+		}
+		hasPrimary, err := hasPrimaryAddress(tx.db, address.ContactID)
+		if err != nil {
+			return err
+		}
+		// a contact's first address is its primary
+		if !hasPrimary {
+			address.IsPrimary = true
+		} else if address.IsPrimary {
+			if err := clearPrimary(tx.db, "addresses", address.ContactID, 0); err != nil {
+				return err
+			}
+		}
+		if err := tx.db.Create(&address).Error; err != nil {
+			return err
+		}
+		if address.Lat == nil || address.Lng == nil {
+			tx.geocodeLater(address.ID)
+		}
+		return tx.auditChange(AuditActionCreate, AuditEntityAddress, address.ID, address.ContactID, nil, address)
+	})
+	if err != nil {
+		return Address{}, err
 	}
 	return address, nil
 }
 
-func (a AddressProvider) Get(id int) (Address, error) {
+func (a AddressProvider) Get(id int) (_ Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.Get")
+	defer func() { endSpan(span, err) }()
+
 	address := Address{ID: id}
-	if db := a.db.Take(&address); db.Error != nil {
+	if db := traced.db.Take(&address); db.Error != nil {
 		if IsNotFound(db.Error) {
 			return Address{}, &recordNotFound{"get address", address.ID}
 		}
@@ -28,66 +65,199 @@ func (a AddressProvider) Get(id int) (Address, error) {
 	return address, nil
 }
 
-func (a AddressProvider) GetAll() ([]Address, error) {
+func (a AddressProvider) GetAll() (_ []Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.GetAll")
+	defer func() { endSpan(span, err) }()
+
 	addresses := make([]Address, 0)
-	if db := a.db.Order("id").Find(&addresses); db.Error != nil {
+	if db := traced.db.Order("id").Find(&addresses); db.Error != nil {
 		return nil, db.Error
 	}
 	return addresses, nil
 }
 
-func (a AddressProvider) GetAllByContactID(contactID int) ([]Address, error) {
-	_, err := a.parent.Contacts.Get(contactID)
+func (a AddressProvider) GetAllByContactID(contactID int) (_ []Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.GetAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
 	if err != nil {
 		return nil, err
 	}
 
 	addresses := make([]Address, 0)
-	if db := a.db.Order("id").Where(Address{ContactID: contactID}).Find(&addresses); db.Error != nil {
+	if db := traced.db.Order("id").Where(Address{ContactID: contactID}).Find(&addresses); db.Error != nil {
 		return nil, db.Error
 	}
 	return addresses, nil
 }
 
-func (a AddressProvider) Update(address Address) (Address, error) {
-	existing, err := a.Get(address.ID)
-	if err != nil {
-		if IsNotFound(err) {
-			return Address{}, &recordNotFound{"update address", address.ID}
+// ReplaceAllByContactID deletes the contact's addresses and creates addresses in their place, in
+// one transaction, returning the new addresses.
+func (a AddressProvider) ReplaceAllByContactID(contactID int, addresses []Address) (_ []Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.ReplaceAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	created := make([]Address, 0, len(addresses))
+	err = traced.Transaction(func(tx DB) error {
+		if err := tx.Addresses.DeleteAllByContactID(contactID); err != nil {
+			return err
 		}
-		return Address{}, err
+		for _, address := range addresses {
+			address.ContactID = contactID
+			address, err := tx.Addresses.Create(address)
+			if err != nil {
+				return err
+			}
+			created = append(created, address)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return created, nil
+}
 
-	if address.CreatedAt.IsZero() {
-		address.CreatedAt = existing.CreatedAt
-	}
+func (a AddressProvider) Update(address Address) (_ Address, err error) {
+	traced, span := a.parent.startSpan("AddressProvider.Update")
+	defer func() { endSpan(span, err) }()
 
-	if db := a.db.Save(&address); db.Error != nil {
-		return Address{}, db.Error
+	err = traced.Transaction(func(tx DB) error {
+		existing, err := tx.Addresses.Get(address.ID)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"update address", address.ID}
+			}
+			return err
+		}
+
+		if address.CreatedAt.IsZero() {
+			address.CreatedAt = existing.CreatedAt
+		}
+		// an address updated without a country stays in the country it's in
+		if address.Country == "" {
+			address.Country = existing.Country
+		}
+		if address, err = normalizeAddress("update address", address); err != nil {
+			return err
+		}
+		// the coordinates still apply if the address hasn't moved; otherwise it's geocoded again
+		if address.Lat == nil && address.Lng == nil && postalAddress(address) == postalAddress(existing) {
+			address.Lat, address.Lng = existing.Lat, existing.Lng
+		}
+
+		moved := address.ContactID != existing.ContactID
+		if err := lockContacts(tx.db, "update address", existing.ContactID, address.ContactID); err != nil {
+			return err
+		}
+
+		// the primary address only changes when another address is made primary, so the contact
+		// always has one. An address moved to another contact is only primary there if it's asked
+		// to be or the contact has no primary yet.
+		switch {
+		case address.IsPrimary:
+			if err := clearPrimary(tx.db, "addresses", address.ContactID, address.ID); err != nil {
+				return err
+			}
+		case moved:
+			hasPrimary, err := hasPrimaryAddress(tx.db, address.ContactID)
+			if err != nil {
+				return err
+			}
+			address.IsPrimary = !hasPrimary
+		case existing.IsPrimary:
+			address.IsPrimary = true
+		}
+
+		if err := tx.db.Save(&address).Error; err != nil {
+			return err
+		}
+		// the contact it moved from needs a new primary, as if the address had been deleted
+		if moved && existing.IsPrimary {
+			if err := promotePrimaryAddress(tx.db, existing.ContactID); err != nil {
+				return err
+			}
+		}
+		if address.Lat == nil || address.Lng == nil {
+			tx.geocodeLater(address.ID)
+		}
+		return tx.auditChange(AuditActionUpdate, AuditEntityAddress, address.ID, address.ContactID, existing, address)
+	})
+	if err != nil {
+		return Address{}, err
 	}
 	return address, nil
 }
 
-func (a AddressProvider) Delete(id int) error {
-	db := a.db.Delete(&Address{ID: id})
-	if db.Error != nil {
-		return db.Error
-	}
-	if db.RowsAffected == 0 {
-		return &recordNotFound{"delete address", id}
-	}
-	return nil
+// Delete deletes the address. If it was the contact's primary address, the contact's oldest
+// remaining address becomes primary in its place.
+func (a AddressProvider) Delete(id int) (err error) {
+	traced, span := a.parent.startSpan("AddressProvider.Delete")
+	defer func() { endSpan(span, err) }()
+
+	return traced.Transaction(func(tx DB) error {
+		address, err := tx.Addresses.Get(id)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"delete address", id}
+			}
+			return err
+		}
+		if err := lockContacts(tx.db, "delete address", address.ContactID); err != nil {
+			return err
+		}
+
+		if err := tx.db.Delete(&address).Error; err != nil {
+			return err
+		}
+		if address.IsPrimary {
+			if err := promotePrimaryAddress(tx.db, address.ContactID); err != nil {
+				return err
+			}
+		}
+		return tx.auditChange(AuditActionDelete, AuditEntityAddress, address.ID, address.ContactID, address, nil)
+	})
 }
 
-func (a AddressProvider) DeleteAllByContactID(contactID int) error {
-	_, err := a.parent.Contacts.Get(contactID)
-	if err != nil {
-		return err
-	}
+func (a AddressProvider) DeleteAllByContactID(contactID int) (err error) {
+	traced, span := a.parent.startSpan("AddressProvider.DeleteAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	return traced.Transaction(func(tx DB) error {
+		// read first, for the audit log
+		addresses, err := tx.Addresses.GetAllByContactID(contactID)
+		if err != nil {
+			return err
+		}
 
-	db := a.db.Delete(&Address{ContactID: contactID})
-	if db.Error != nil {
-		return db.Error
+		// gorm only uses the primary key of the value passed to Delete, so the contact has to be
+		// filtered with Where or every address would be deleted
+		if err := tx.db.Where("contact_id = ?", contactID).Delete(&Address{}).Error; err != nil {
+			return err
+		}
+		for _, address := range addresses {
+			if err := tx.auditChange(AuditActionDelete, AuditEntityAddress, address.ID, contactID, address, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// hasPrimaryAddress reports whether the contact has a primary address, which it does if it has any
+// addresses.
+func hasPrimaryAddress(db *gorm.DB, contactID int) (bool, error) {
+	var count int
+	if err := db.Model(&Address{}).Where("contact_id = ? AND is_primary", contactID).Count(&count).Error; err != nil {
+		return false, err
 	}
-	return nil
+	return count > 0, nil
+}
+
+// promotePrimaryAddress makes the contact's oldest address its primary, after its primary address
+// was deleted.
+func promotePrimaryAddress(db *gorm.DB, contactID int) error {
+	return db.Exec(`UPDATE addresses SET is_primary = TRUE
+		WHERE id = (SELECT min(id) FROM addresses WHERE contact_id = ?)`, contactID).Error
 }
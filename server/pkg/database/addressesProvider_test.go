@@ -0,0 +1,224 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestAddressProvider_CreateMakesFirstAddressPrimary(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	first, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "2 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "3 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701", IsPrimary: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if !first.IsPrimary || second.IsPrimary || !third.IsPrimary {
+		t.Errorf("created primaries, want: [true false true] got: [%v %v %v]", first.IsPrimary, second.IsPrimary, third.IsPrimary)
+	}
+	if stored, err := db.Addresses.Get(first.ID); err != nil || stored.IsPrimary {
+		t.Errorf("previous primary, want: not primary got: %+v (%v)", stored, err)
+	}
+}
+
+func TestAddressProvider_UpdateKeepsPrimaryUntilAnotherIsMadePrimary(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "2 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	first.IsPrimary = false
+	kept, err := db.Addresses.Update(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.IsPrimary = true
+	promoted, err := db.Addresses.Update(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if !kept.IsPrimary {
+		t.Errorf("primary updated without isPrimary, want: primary got: %+v", kept)
+	}
+	if !promoted.IsPrimary {
+		t.Errorf("address made primary, want: primary got: %+v", promoted)
+	}
+	if stored, err := db.Addresses.Get(first.ID); err != nil || stored.IsPrimary {
+		t.Errorf("previous primary, want: not primary got: %+v (%v)", stored, err)
+	}
+}
+
+func TestAddressProvider_DeletePromotesOldestAddress(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addresses []Address
+	for _, line1 := range []string{"1 Main St", "2 Main St", "3 Main St"} {
+		address, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: line1, City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addresses = append(addresses, address)
+	}
+
+	// act
+	if err = db.Addresses.Delete(addresses[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	remaining, err := db.Addresses.GetAllByContactID(contact.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 2 || remaining[0].ID != addresses[1].ID {
+		t.Fatalf("remaining addresses, want: [%d %d] got: %+v", addresses[1].ID, addresses[2].ID, remaining)
+	}
+	if !remaining[0].IsPrimary || remaining[1].IsPrimary {
+		t.Errorf("primaries, want: [true false] got: [%v %v]", remaining[0].IsPrimary, remaining[1].IsPrimary)
+	}
+}
+
+func TestAddressProvider_UpdateMovingPrimaryToAnotherContact(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := db.Contacts.Create(Contact{FirstName: "Jane", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moving, err := db.Addresses.Create(Address{ContactID: from.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	staying, err := db.Addresses.Create(Address{ContactID: from.ID, Line1: "2 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Addresses.Create(Address{ContactID: to.ID, Line1: "3 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	moving.ContactID = to.ID
+	moved, err := db.Addresses.Update(moving)
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.IsPrimary {
+		t.Errorf("moved address, want: not primary, the contact has one got: %+v", moved)
+	}
+	if stored, err := db.Addresses.Get(staying.ID); err != nil || !stored.IsPrimary {
+		t.Errorf("address left behind, want: promoted to primary got: %+v (%v)", stored, err)
+	}
+}
+
+func TestAddressProvider_ConcurrentFirstAddressesHaveOnePrimary(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	errs := make(chan error, 5)
+	for i := 0; i < cap(errs); i++ {
+		go func(i int) {
+			_, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: fmt.Sprintf("%d Main St", i+1), City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+			errs <- err
+		}(i)
+	}
+
+	// assert
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("create, want: nil got: %v", err)
+		}
+	}
+	addresses, err := db.Addresses.GetAllByContactID(contact.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	primaries := 0
+	for _, address := range addresses {
+		if address.IsPrimary {
+			primaries++
+		}
+	}
+	if len(addresses) != 5 || primaries != 1 {
+		t.Errorf("addresses, want: 5 with 1 primary got: %d with %d", len(addresses), primaries)
+	}
+}
+
+func TestAddressProvider_DeleteReturnsErrorIfNotFound(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	err = db.Addresses.Delete(-1)
+
+	// assert
+	if !IsNotFound(err) {
+		t.Errorf("err, want: not found got: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/auth"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+)
+
+type AuditProvider struct {
+	db     *gorm.DB
+	parent *DB
+}
+
+// AuditFilter narrows the entries returned by AuditProvider.Find. Empty fields don't filter.
+type AuditFilter struct {
+	ContactID int // entries about the contact or one of its addresses
+	Entity    string
+	EntityID  int
+	Action    string
+	Actor     string
+	RequestID string
+	Since     time.Time // entries recorded at or after Since
+	Until     time.Time // entries recorded before Until
+	BeforeID  int       // entries older than this one, to page through the log
+}
+
+func (f AuditFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.ContactID != 0 {
+		db = db.Where("contact_id = ?", f.ContactID)
+	}
+	if f.Entity != "" {
+		db = db.Where("entity = ?", f.Entity)
+	}
+	if f.EntityID != 0 {
+		db = db.Where("entity_id = ?", f.EntityID)
+	}
+	if f.Action != "" {
+		db = db.Where("action = ?", f.Action)
+	}
+	if f.Actor != "" {
+		db = db.Where("actor = ?", f.Actor)
+	}
+	if f.RequestID != "" {
+		db = db.Where("request_id = ?", f.RequestID)
+	}
+	if !f.Since.IsZero() {
+		db = db.Where("created_at >= ?", f.Since)
+	}
+	if !f.Until.IsZero() {
+		db = db.Where("created_at < ?", f.Until)
+	}
+	if f.BeforeID != 0 {
+		db = db.Where("id < ?", f.BeforeID)
+	}
+	return db
+}
+
+// Create records an entry. Call it in the transaction making the change, so the entry is only
+// recorded if the change is. An entry without an actor, request ID or IP takes them from the
+// context the DB is bound to, see WithContext.
+func (a AuditProvider) Create(entry AuditEntry) (_ AuditEntry, err error) {
+	traced, span := a.parent.startSpan("AuditProvider.Create")
+	defer func() { endSpan(span, err) }()
+
+	if entry.ID != 0 {
+		return AuditEntry{}, &invalidRequest{"create audit entry", "id must be 0"}
+	}
+
+	ctx := dbContext(traced.db)
+	if entry.Actor == "" {
+		entry.Actor = auth.UserID(ctx)
+	}
+	if entry.RequestID == "" {
+		entry.RequestID = requestid.FromContext(ctx)
+	}
+	if entry.IP == "" {
+		entry.IP = clientip.FromContext(ctx)
+	}
+
+	if db := traced.db.Create(&entry); db.Error != nil {
+		return AuditEntry{}, db.Error
+	}
+	return entry, nil
+}
+
+// GetAllByEntity returns the entries recorded for an entity, oldest first.
+func (a AuditProvider) GetAllByEntity(entity string, entityID int) (_ []AuditEntry, err error) {
+	traced, span := a.parent.startSpan("AuditProvider.GetAllByEntity")
+	defer func() { endSpan(span, err) }()
+
+	entries := make([]AuditEntry, 0)
+	if db := traced.db.Order("id").Where(AuditEntry{Entity: entity, EntityID: entityID}).Find(&entries); db.Error != nil {
+		return nil, db.Error
+	}
+	return entries, nil
+}
+
+// Find returns at most limit entries matching filter, newest first.
+func (a AuditProvider) Find(filter AuditFilter, limit int) (_ []AuditEntry, err error) {
+	traced, span := a.parent.startSpan("AuditProvider.Find")
+	defer func() { endSpan(span, err) }()
+
+	if limit <= 0 {
+		return nil, &invalidRequest{"find audit entries", "limit must be greater than 0"}
+	}
+
+	entries := make([]AuditEntry, 0)
+	if db := filter.apply(traced.db).Order("id desc").Limit(limit).Find(&entries); db.Error != nil {
+		return nil, db.Error
+	}
+	return entries, nil
+}
+
+// auditChange records a create, update or delete of an entity of the contact. before is nil for a
+// create and after nil for a delete. An update that changed nothing isn't recorded.
+func (d DB) auditChange(action, entity string, entityID, contactID int, before, after interface{}) error {
+	entry := AuditEntry{Action: action, Entity: entity, EntityID: entityID, ContactID: &contactID}
+	changed, err := setAuditValues(&entry, before, after)
+	if err != nil || !changed {
+		return err
+	}
+	_, err = d.Audit.Create(entry)
+	return err
+}
+
+// setAuditValues sets the entry's Before and After to the JSON of the entity before and after the
+// change. For an update only the fields that changed are kept; UpdatedAt always changes, so isn't
+// compared. It reports whether anything changed.
+func setAuditValues(entry *AuditEntry, before, after interface{}) (changed bool, err error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return false, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return false, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for name, value := range beforeFields {
+			if name == "updatedAt" || reflect.DeepEqual(value, afterFields[name]) {
+				delete(beforeFields, name)
+				delete(afterFields, name)
+			}
+		}
+		if len(beforeFields) == 0 && len(afterFields) == 0 {
+			return false, nil
+		}
+	}
+
+	if entry.Before, err = auditJSON(beforeFields); err != nil {
+		return false, err
+	}
+	if entry.After, err = auditJSON(afterFields); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// auditFields returns the fields of an entity by their json names, or nil for a nil entity.
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func auditJSON(fields map[string]interface{}) (*string, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	return &s, nil
+}
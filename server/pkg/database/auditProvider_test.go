@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/auth"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/clientip"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/requestid"
+)
+
+func TestSetAuditValues(t *testing.T) {
+	company := "Acme Corp."
+	before := Contact{ID: 1, FirstName: "John", LastName: "Doe", UpdatedAt: time.Unix(10, 0)}
+	after := Contact{ID: 1, FirstName: "John", LastName: "Doe", Company: &company, UpdatedAt: time.Unix(20, 0)}
+
+	tests := []struct {
+		name          string
+		before, after interface{}
+		wantChanged   bool
+		wantBefore    map[string]interface{}
+		wantAfter     map[string]interface{}
+	}{
+		{"create", nil, after, true, nil, map[string]interface{}{"company": company}},
+		{"delete", before, nil, true, map[string]interface{}{"company": nil}, nil},
+		{"update", before, after, true, map[string]interface{}{"company": nil}, map[string]interface{}{"company": company}},
+		{"unchanged", before, before, false, nil, nil},
+		{"only updated", before, Contact{ID: 1, FirstName: "John", LastName: "Doe", UpdatedAt: time.Unix(20, 0)}, false, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// arrange
+			var entry AuditEntry
+
+			// act
+			changed, err := setAuditValues(&entry, tt.before, tt.after)
+
+			// assert
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.wantChanged {
+				t.Fatalf("changed, want: %v got: %v", tt.wantChanged, changed)
+			}
+			if !changed {
+				return
+			}
+			// an update records only what changed; a create or delete records every field
+			update := tt.before != nil && tt.after != nil
+			checkAuditValue(t, "before", entry.Before, tt.wantBefore, update)
+			checkAuditValue(t, "after", entry.After, tt.wantAfter, update)
+		})
+	}
+}
+
+// checkAuditValue checks the JSON value has the wanted fields, and only those if exact.
+func checkAuditValue(t *testing.T, name string, value *string, want map[string]interface{}, exact bool) {
+	t.Helper()
+	if want == nil {
+		if value != nil {
+			t.Errorf("%s, want: nil got: %s", name, *value)
+		}
+		return
+	}
+	if value == nil {
+		t.Fatalf("%s, want: %v got: nil", name, want)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(*value), &got); err != nil {
+		t.Fatal(err)
+	}
+	for field, wantValue := range want {
+		if gotValue, ok := got[field]; !ok || gotValue != wantValue {
+			t.Errorf("%s.%s, want: %v got: %v", name, field, wantValue, gotValue)
+		}
+	}
+	if exact && len(got) != len(want) {
+		t.Errorf("%s, want: %v got: %s", name, want, *value)
+	}
+}
+
+func TestContactProvider_UpdateRecordsAuditEntry(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := auth.NewContext(context.Background(), "user-1")
+	ctx = requestid.NewContext(ctx, "abc")
+	ctx = clientip.NewContext(ctx, "203.0.113.7")
+	db = db.WithContext(ctx)
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	contact.FirstName = "Jane"
+	if _, err = db.Contacts.Update(contact); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	entries, err := db.Audit.Find(AuditFilter{ContactID: contact.ID}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Action != AuditActionUpdate || entries[1].Action != AuditActionCreate {
+		t.Fatalf("entries, want: update and create got: %+v", entries)
+	}
+	update := entries[0]
+	if update.Actor != "user-1" || update.RequestID != "abc" || update.IP != "203.0.113.7" {
+		t.Errorf("request, want: user-1, abc and 203.0.113.7 got: %q, %q and %q", update.Actor, update.RequestID, update.IP)
+	}
+	checkAuditValue(t, "before", update.Before, map[string]interface{}{"firstName": "John"}, true)
+	checkAuditValue(t, "after", update.After, map[string]interface{}{"firstName": "Jane"}, true)
+}
+
+func TestAddressProvider_ChangesAppearInContactHistory(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	if err = db.Addresses.Delete(address.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	entries, err := db.Audit.Find(AuditFilter{ContactID: contact.ID, Entity: AuditEntityAddress}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Action != AuditActionDelete || entries[1].Action != AuditActionCreate {
+		t.Fatalf("entries, want: delete and create got: %+v", entries)
+	}
+	if entries[0].EntityID != address.ID || entries[0].Before == nil || entries[0].After != nil {
+		t.Errorf("delete, want: the address before and nothing after got: %+v", entries[0])
+	}
+}
+
+func TestAuditProvider_FindRejectsZeroLimit(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Audit.Find(AuditFilter{}, 0)
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+}
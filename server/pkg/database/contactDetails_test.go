@@ -0,0 +1,185 @@
+package database
+
+import "testing"
+
+func TestEmailProvider_CreatePrimaryClearsOtherPrimary(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := db.Emails.Create(Email{ContactID: contact.ID, Type: EmailTypeWork, Address: "john@example.com", IsPrimary: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	second, err := db.Emails.Create(Email{ContactID: contact.ID, Type: EmailTypeHome, Address: "john@home.example.com", IsPrimary: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	emails, err := db.Emails.GetAllByContactID(contact.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(emails) != 2 {
+		t.Fatalf("len(emails), want: 2 got: %d", len(emails))
+	}
+	if emails[0].ID != second.ID || !emails[0].IsPrimary {
+		t.Errorf("primary, want: %d got: %+v", second.ID, emails[0])
+	}
+	if emails[1].ID != first.ID || emails[1].IsPrimary {
+		t.Errorf("previous primary, want: %d not primary got: %+v", first.ID, emails[1])
+	}
+}
+
+func TestEmailProvider_CreateReturnsNotFoundForMissingContact(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Emails.Create(Email{ContactID: -1, Type: EmailTypeWork, Address: "john@example.com"})
+
+	// assert
+	if !IsNotFound(err) {
+		t.Errorf("err, want: not found got: %v", err)
+	}
+}
+
+func TestPhoneProvider_UpdatePrimaryClearsOtherPrimary(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := db.Phones.Create(Phone{ContactID: contact.ID, Type: PhoneTypeWork, Number: "555 010 0001", IsPrimary: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := db.Phones.Create(Phone{ContactID: contact.ID, Type: PhoneTypeMobile, Number: "555 010 0002"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	second.IsPrimary = true
+	if _, err = db.Phones.Update(second); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	got, err := db.Phones.Get(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsPrimary {
+		t.Errorf("previous primary IsPrimary, want: false got: true")
+	}
+}
+
+func TestTagProvider_CreateRejectsDuplicateName(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Tags.Create(Tag{ContactID: contact.ID, Name: "Customer"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Tags.Create(Tag{ContactID: contact.ID, Name: "customer"})
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+}
+
+func TestContactProvider_DeleteDeletesDetails(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, err := db.Emails.Create(Email{ContactID: contact.ID, Type: EmailTypeWork, Address: "john@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := db.Tags.Create(Tag{ContactID: contact.ID, Name: "customer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	if err = db.Contacts.Delete(contact.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if _, err := db.Emails.Get(email.ID); !IsNotFound(err) {
+		t.Errorf("Get email, want: not found got: %v", err)
+	}
+	if _, err := db.Tags.Get(tag.ID); !IsNotFound(err) {
+		t.Errorf("Get tag, want: not found got: %v", err)
+	}
+}
+
+func TestDetailProviders_GetZeroIDReturnsNotFound(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Emails.Create(Email{ContactID: contact.ID, Type: EmailTypeWork, Address: "john@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Phones.Create(Phone{ContactID: contact.ID, Type: PhoneTypeMobile, Number: "+12175550100"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Tags.Create(Tag{ContactID: contact.ID, Name: "friend"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, emailErr := db.Emails.Get(0)
+	_, phoneErr := db.Phones.Get(0)
+	_, tagErr := db.Tags.Get(0)
+
+	// assert
+	for name, err := range map[string]error{"email": emailErr, "phone": phoneErr, "tag": tagErr} {
+		if !IsNotFound(err) {
+			t.Errorf("%s, want: not found got: %v", name, err)
+		}
+	}
+}
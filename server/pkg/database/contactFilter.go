@@ -0,0 +1,31 @@
+package database
+
+import (
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ContactFilter narrows the contacts returned by ContactProvider.Find and Each. Empty fields
+// don't filter.
+type ContactFilter struct {
+	Name string // part of the first or last name, ignoring case
+	City string // the city of one of the contact's addresses, ignoring case
+	Tag  string // one of the contact's tags, ignoring case
+}
+
+// likeEscaper escapes the LIKE wildcards so they match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (f ContactFilter) apply(db *gorm.DB) *gorm.DB {
+	if f.Name != "" {
+		db = db.Where("contacts.first_name || ' ' || contacts.last_name ILIKE ?", "%"+likeEscaper.Replace(f.Name)+"%")
+	}
+	if f.City != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM addresses WHERE addresses.contact_id = contacts.id AND lower(addresses.city) = lower(?))", f.City)
+	}
+	if f.Tag != "" {
+		db = db.Where("EXISTS (SELECT 1 FROM tags WHERE tags.contact_id = contacts.id AND lower(tags.name) = lower(?))", f.Tag)
+	}
+	return db
+}
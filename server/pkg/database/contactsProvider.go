@@ -1,27 +1,62 @@
 package database
 
 import (
+	"math"
+	"time"
+
 	"github.com/jinzhu/gorm"
 )
 
 type ContactProvider struct {
 	db     *gorm.DB
-	parent DB
+	parent *DB
 }
 
-func (c ContactProvider) Create(contact Contact) (Contact, error) {
+func (c ContactProvider) Create(contact Contact) (_ Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Create")
+	defer func() { endSpan(span, err) }()
+
 	if contact.ID != 0 {
 		return Contact{}, &invalidRequest{"create contact", "id must be 0"}
 	}
-	if db := c.db.Create(&contact); db.Error != nil {
-		return Contact{}, db.Error
+
+	err = traced.Transaction(func(tx DB) error {
+		if err := tx.db.Create(&contact).Error; err != nil {
+			return err
+		}
+		return tx.auditChange(AuditActionCreate, AuditEntityContact, contact.ID, contact.ID, nil, contact)
+	})
+	if err != nil {
+		return Contact{}, err
 	}
 	return contact, nil
 }
 
-func (c ContactProvider) Get(id int) (Contact, error) {
+// CreateWithAddresses creates the contact and its addresses in one transaction.
+func (c ContactProvider) CreateWithAddresses(contact Contact, addresses []Address) (_ Contact, _ []Address, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.CreateWithAddresses")
+	defer func() { endSpan(span, err) }()
+
+	var created []Address
+	err = traced.Transaction(func(tx DB) error {
+		if contact, err = tx.Contacts.Create(contact); err != nil {
+			return err
+		}
+		created, err = tx.Addresses.ReplaceAllByContactID(contact.ID, addresses)
+		return err
+	})
+	if err != nil {
+		return Contact{}, nil, err
+	}
+	return contact, created, nil
+}
+
+func (c ContactProvider) Get(id int) (_ Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Get")
+	defer func() { endSpan(span, err) }()
+
 	contact := Contact{ID: id}
-	if db := c.db.Take(&contact); db.Error != nil {
+	if db := traced.db.Take(&contact); db.Error != nil {
 		if IsNotFound(db.Error) {
 			return Contact{}, &recordNotFound{"get contact", contact.ID}
 		}
@@ -30,44 +65,274 @@ func (c ContactProvider) Get(id int) (Contact, error) {
 	return contact, nil
 }
 
-func (c ContactProvider) GetAll() ([]Contact, error) {
+func (c ContactProvider) GetAll() (_ []Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.GetAll")
+	defer func() { endSpan(span, err) }()
+
 	contacts := make([]Contact, 0)
-	if db := c.db.Order("id").Find(&contacts); db.Error != nil {
+	if db := traced.db.Order("id").Find(&contacts); db.Error != nil {
 		return nil, db.Error
 	}
 	return contacts, nil
 }
 
-func (c ContactProvider) Update(contact Contact) (Contact, error) {
-	existing, err := c.Get(contact.ID)
+func (c ContactProvider) Find(filter ContactFilter) (_ []Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Find")
+	defer func() { endSpan(span, err) }()
+
+	contacts := make([]Contact, 0)
+	if db := filter.apply(traced.db).Order("id").Find(&contacts); db.Error != nil {
+		return nil, db.Error
+	}
+	return contacts, nil
+}
+
+// Each calls fn with every contact matching filter and its addresses, in ID order. Contacts are
+// read from a cursor over a single query rather than loaded up front, so memory use doesn't grow
+// with the number of contacts. It stops at the first error fn returns.
+func (c ContactProvider) Each(filter ContactFilter, fn func(Contact, []Address) error) (err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Each")
+	defer func() { endSpan(span, err) }()
+
+	rows, err := filter.apply(traced.db.Table("contacts")).
+		Select("contacts.id, contacts.first_name, contacts.last_name, contacts.company, contacts.title, contacts.notes, " +
+			"contacts.created_at, contacts.updated_at, " +
+			"addresses.id, addresses.line1, addresses.line2, addresses.city, addresses.state_province, " +
+			"addresses.postal_code, addresses.country, addresses.lat, addresses.lng, addresses.is_primary, " +
+			"addresses.created_at, addresses.updated_at").
+		Joins("LEFT JOIN addresses ON addresses.contact_id = contacts.id").
+		Order("contacts.id, addresses.id").
+		Rows()
 	if err != nil {
-		if IsNotFound(err) {
-			return Contact{}, &recordNotFound{"update contact", contact.ID}
+		return err
+	}
+	defer rows.Close()
+
+	// a contact spans one row per address, or a single row of NULL addresses columns if it has none
+	var (
+		current   *Contact
+		addresses []Address
+	)
+	for rows.Next() {
+		var (
+			contact Contact
+			address struct {
+				ID                                             *int
+				Line1, Line2, City, State, PostalCode, Country *string
+				Lat, Lng                                       *float64
+				IsPrimary                                      *bool
+				CreatedAt, UpdatedAt                           *time.Time
+			}
+		)
+		if err := rows.Scan(&contact.ID, &contact.FirstName, &contact.LastName, &contact.Company, &contact.Title, &contact.Notes,
+			&contact.CreatedAt, &contact.UpdatedAt,
+			&address.ID, &address.Line1, &address.Line2, &address.City, &address.State,
+			&address.PostalCode, &address.Country, &address.Lat, &address.Lng, &address.IsPrimary,
+			&address.CreatedAt, &address.UpdatedAt); err != nil {
+			return err
+		}
+
+		if current == nil || current.ID != contact.ID {
+			if current != nil {
+				if err := fn(*current, addresses); err != nil {
+					return err
+				}
+			}
+			current, addresses = &contact, make([]Address, 0)
+		}
+
+		if address.ID != nil {
+			addresses = append(addresses, Address{
+				ID:            *address.ID,
+				ContactID:     contact.ID,
+				Line1:         *address.Line1,
+				Line2:         address.Line2,
+				City:          *address.City,
+				StateProvince: *address.State,
+				PostalCode:    *address.PostalCode,
+				Country:       *address.Country,
+				Lat:           address.Lat,
+				Lng:           address.Lng,
+				IsPrimary:     *address.IsPrimary,
+				CreatedAt:     *address.CreatedAt,
+				UpdatedAt:     *address.UpdatedAt,
+			})
 		}
-		return Contact{}, err
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	if contact.CreatedAt.IsZero() {
-		contact.CreatedAt = existing.CreatedAt
+	if current != nil {
+		return fn(*current, addresses)
 	}
+	return nil
+}
 
-	if db := c.db.Save(&contact); db.Error != nil {
-		return Contact{}, db.Error
+// NearbyContact is a contact found by Near, with its address nearest the point and the distance
+// to it in kilometres.
+type NearbyContact struct {
+	Contact  Contact
+	Address  Address
+	Distance float64
+}
+
+const (
+	earthRadiusKm = 6371.0088
+	kmPerDegree   = earthRadiusKm * math.Pi / 180
+)
+
+// Near returns the contacts with a geocoded address within radius kilometres of the point, nearest
+// first. Distances are great-circle distances on a spherical Earth.
+func (c ContactProvider) Near(lat, lng, radius float64) (_ []NearbyContact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Near")
+	defer func() { endSpan(span, err) }()
+
+	switch {
+	case lat < -90 || lat > 90:
+		return nil, &invalidRequest{"find contacts near", "lat must be between -90 and 90"}
+	case lng < -180 || lng > 180:
+		return nil, &invalidRequest{"find contacts near", "lng must be between -180 and 180"}
+	case radius <= 0:
+		return nil, &invalidRequest{"find contacts near", "radius must be greater than 0"}
+	}
+
+	// the haversine formula; least guards asin against rounding just past 1
+	distance := `2 * ? * asin(least(1, sqrt(
+		power(sin(radians(addresses.lat - ?) / 2), 2) +
+		cos(radians(?)) * cos(radians(addresses.lat)) * power(sin(radians(addresses.lng - ?) / 2), 2))))`
+	args := []interface{}{earthRadiusKm, lat, lat, lng}
+
+	// the circle fits in a box of latitudes and longitudes, which the index on (lat, lng) can
+	// search before any distances are computed. Where the box would cross a pole or the
+	// antimeridian only the latitudes are bounded.
+	dLat := radius / kmPerDegree
+	box := "addresses.lat BETWEEN ? AND ?"
+	args = append(args, lat-dLat, lat+dLat)
+	if lat-dLat > -90 && lat+dLat < 90 {
+		dLng := dLat / math.Cos(lat*math.Pi/180)
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			box += " AND addresses.lng BETWEEN ? AND ?"
+			args = append(args, lng-dLng, lng+dLng)
+		}
+	}
+	args = append(args, radius)
+
+	rows, err := traced.db.Raw(`SELECT contacts.id, contacts.first_name, contacts.last_name, contacts.company,
+			contacts.title, contacts.notes, contacts.created_at, contacts.updated_at,
+			addresses.id, addresses.contact_id, addresses.line1, addresses.line2, addresses.city,
+			addresses.state_province, addresses.postal_code, addresses.country, addresses.lat, addresses.lng,
+			addresses.is_primary, addresses.created_at, addresses.updated_at, nearest.distance
+		FROM (
+			SELECT DISTINCT ON (addresses.contact_id) addresses.id, `+distance+` AS distance
+			FROM addresses
+			WHERE `+box+`
+			ORDER BY addresses.contact_id, distance
+		) nearest
+		JOIN addresses ON addresses.id = nearest.id
+		JOIN contacts ON contacts.id = addresses.contact_id
+		WHERE nearest.distance <= ?
+		ORDER BY nearest.distance, contacts.id`, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nearby := make([]NearbyContact, 0)
+	for rows.Next() {
+		var n NearbyContact
+		if err := rows.Scan(&n.Contact.ID, &n.Contact.FirstName, &n.Contact.LastName, &n.Contact.Company,
+			&n.Contact.Title, &n.Contact.Notes, &n.Contact.CreatedAt, &n.Contact.UpdatedAt,
+			&n.Address.ID, &n.Address.ContactID, &n.Address.Line1, &n.Address.Line2, &n.Address.City,
+			&n.Address.StateProvince, &n.Address.PostalCode, &n.Address.Country, &n.Address.Lat, &n.Address.Lng,
+			&n.Address.IsPrimary, &n.Address.CreatedAt, &n.Address.UpdatedAt, &n.Distance); err != nil {
+			return nil, err
+		}
+		nearby = append(nearby, n)
+	}
+	return nearby, rows.Err()
+}
+
+func (c ContactProvider) Update(contact Contact) (_ Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Update")
+	defer func() { endSpan(span, err) }()
+
+	err = traced.Transaction(func(tx DB) error {
+		existing, err := tx.Contacts.Get(contact.ID)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"update contact", contact.ID}
+			}
+			return err
+		}
+
+		if contact.CreatedAt.IsZero() {
+			contact.CreatedAt = existing.CreatedAt
+		}
+
+		if err := tx.db.Save(&contact).Error; err != nil {
+			return err
+		}
+		return tx.auditChange(AuditActionUpdate, AuditEntityContact, contact.ID, contact.ID, existing, contact)
+	})
+	if err != nil {
+		return Contact{}, err
 	}
 	return contact, nil
 }
 
-func (c ContactProvider) Delete(id int) error {
-	if err := c.parent.Addresses.DeleteAllByContactID(id); err != nil {
+// UpdateWithAddresses updates the contact and replaces its addresses in one transaction, returning
+// the contact's addresses afterwards. If addresses is nil the addresses are left as they are.
+func (c ContactProvider) UpdateWithAddresses(contact Contact, addresses []Address) (_ Contact, _ []Address, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.UpdateWithAddresses")
+	defer func() { endSpan(span, err) }()
+
+	var current []Address
+	err = traced.Transaction(func(tx DB) error {
+		if contact, err = tx.Contacts.Update(contact); err != nil {
+			return err
+		}
+		if addresses == nil {
+			current, err = tx.Addresses.GetAllByContactID(contact.ID)
+		} else {
+			current, err = tx.Addresses.ReplaceAllByContactID(contact.ID, addresses)
+		}
 		return err
+	})
+	if err != nil {
+		return Contact{}, nil, err
 	}
+	return contact, current, nil
+}
 
-	db := c.db.Delete(&Contact{ID: id})
-	if db.Error != nil {
-		return db.Error
-	}
-	if db.RowsAffected == 0 {
-		return &recordNotFound{"delete contact", id}
-	}
-	return nil
+func (c ContactProvider) Delete(id int) (err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Delete")
+	defer func() { endSpan(span, err) }()
+
+	return traced.Transaction(func(tx DB) error {
+		contact, err := tx.Contacts.Get(id)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"delete contact", id}
+			}
+			return err
+		}
+
+		// the contact's children first, for their foreign keys
+		for _, deleteAll := range []func(int) error{
+			tx.Addresses.DeleteAllByContactID,
+			tx.Emails.DeleteAllByContactID,
+			tx.Phones.DeleteAllByContactID,
+			tx.Tags.DeleteAllByContactID,
+		} {
+			if err := deleteAll(id); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.db.Delete(&contact).Error; err != nil {
+			return err
+		}
+		return tx.auditChange(AuditActionDelete, AuditEntityContact, contact.ID, contact.ID, contact, nil)
+	})
 }
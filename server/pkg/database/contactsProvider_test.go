@@ -464,3 +464,204 @@ func TestContactProvider_DeleteReturnsErrorIfNotFound(t *testing.T) {
 		t.Fatal("expected error, got <nil>")
 	}
 }
+
+func TestContactProvider_FindFiltersByNameAndCity(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	jane, err := db.Contacts.Create(Contact{FirstName: "Jane", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Addresses.Create(Address{ContactID: jane.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	byName, err := db.Contacts.Find(ContactFilter{Name: "jane d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byCity, err := db.Contacts.Find(ContactFilter{City: "SPRINGFIELD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wildcard, err := db.Contacts.Find(ContactFilter{Name: "%"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(byName) != 1 || byName[0].ID != jane.ID {
+		t.Errorf("Find by name, want: [%d] got: %v", jane.ID, byName)
+	}
+	if len(byCity) != 1 || byCity[0].ID != jane.ID {
+		t.Errorf("Find by city, want: [%d] got: %v", jane.ID, byCity)
+	}
+	if len(wildcard) != 0 {
+		t.Errorf("Find by %%, want: none got: %v", wildcard)
+	}
+}
+
+func TestContactProvider_EachGroupsAddressesByContact(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	jane, err := db.Contacts.Create(Contact{FirstName: "Jane", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	john, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, city := range []string{"Springfield", "Shelbyville"} {
+		if _, err = db.Addresses.Create(Address{ContactID: jane.ID, Line1: "1 Main St", City: city, StateProvince: "IL", PostalCode: "62701"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// act
+	var ids, counts []int
+	err = db.Contacts.Each(ContactFilter{}, func(contact Contact, addresses []Address) error {
+		ids = append(ids, contact.ID)
+		counts = append(counts, len(addresses))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(ids) != 2 || ids[0] != jane.ID || ids[1] != john.ID {
+		t.Fatalf("contact IDs, want: [%d %d] got: %v", jane.ID, john.ID, ids)
+	}
+	if counts[0] != 2 || counts[1] != 0 {
+		t.Errorf("address counts, want: [2 0] got: %v", counts)
+	}
+}
+
+func TestContactProvider_CreateWithAddresses(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addresses := []Address{
+		{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"},
+		{Line1: "2 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"},
+	}
+
+	// act
+	contact, created, err := db.Contacts.CreateWithAddresses(Contact{FirstName: "John", LastName: "Doe"}, addresses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	stored, err := db.Addresses.GetAllByContactID(contact.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || len(stored) != 2 {
+		t.Fatalf("addresses, want: 2 got: %d created and %d stored", len(created), len(stored))
+	}
+	for i := range created {
+		if created[i].ID != stored[i].ID || stored[i].ContactID != contact.ID || stored[i].Line1 != addresses[i].Line1 {
+			t.Errorf("address %d, want: %+v got: %+v", i, created[i], stored[i])
+		}
+	}
+}
+
+func TestContactProvider_CreateWithAddressesRollsBackOnError(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := db.Contacts.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act; an address with an ID can't be created
+	_, _, err = db.Contacts.CreateWithAddresses(Contact{FirstName: "John", LastName: "Doe"}, []Address{{ID: 1}})
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+	after, err := db.Contacts.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Errorf("contacts, want: %d got: %d", len(before), len(after))
+	}
+}
+
+func TestContactProvider_UpdateWithAddresses(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contact, _, err := db.Contacts.CreateWithAddresses(Contact{FirstName: "John", LastName: "Doe"}, []Address{
+		{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	contact.FirstName = "Jane"
+	_, kept, err := db.Contacts.UpdateWithAddresses(contact, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, replaced, err := db.Contacts.UpdateWithAddresses(contact, []Address{
+		{Line1: "2 Main St", City: "Shelbyville", StateProvince: "IL", PostalCode: "62565"},
+		{Line1: "3 Main St", City: "Shelbyville", StateProvince: "IL", PostalCode: "62565"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, cleared, err := db.Contacts.UpdateWithAddresses(contact, []Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(kept) != 1 || kept[0].Line1 != "1 Main St" {
+		t.Errorf("nil addresses, want: the existing address got: %+v", kept)
+	}
+	if len(replaced) != 2 || replaced[0].City != "Shelbyville" {
+		t.Errorf("replaced addresses, want: 2 in Shelbyville got: %+v", replaced)
+	}
+	if len(cleared) != 0 {
+		t.Errorf("empty addresses, want: none got: %+v", cleared)
+	}
+	if updated, err := db.Contacts.Get(contact.ID); err != nil || updated.FirstName != "Jane" {
+		t.Errorf("FirstName, want: Jane got: %q (%v)", updated.FirstName, err)
+	}
+}
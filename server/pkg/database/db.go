@@ -1,16 +1,34 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/postgres"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/geocode"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// minConnectBackoff and maxConnectBackoff bound the delay between connection attempts in New.
+	minConnectBackoff = 100 * time.Millisecond
+	maxConnectBackoff = 30 * time.Second
 )
 
 type DB struct {
 	db        *gorm.DB
+	metrics   *metrics
+	geocoding *geocoding
+	pending   *[]func() // run after the transaction commits, see afterCommit
 	Contacts  *ContactProvider
 	Addresses *AddressProvider
+	Emails    *EmailProvider
+	Phones    *PhoneProvider
+	Tags      *TagProvider
+	Audit     *AuditProvider
 }
 
 type Settings struct {
@@ -20,20 +38,143 @@ type Settings struct {
 	Password string
 	DBName   string
 	SSLMode  string // disable, require, verify-ca, verify-full. see https://godoc.org/github.com/lib/pq
+
+	// connection pool settings; a zero value leaves the database/sql default in place.
+	MaxOpenConns    int           // maximum number of open connections (default unlimited)
+	MaxIdleConns    int           // maximum number of idle connections (default 2)
+	ConnMaxLifetime time.Duration // maximum time a connection may be reused (default forever)
+
+	// ConnectRetries is the number of times New retries a failed connection before giving up.
+	// the delay between attempts starts at ConnectBackoff, or 100ms if it is less, and doubles after every failure.
+	ConnectRetries int
+	ConnectBackoff time.Duration
+
+	// Geocoder, if set, looks up the coordinates of addresses in the background after they're
+	// created or updated.
+	Geocoder geocode.Geocoder
 }
 
+// PoolStats is a snapshot of the connection pool, see sql.DBStats.
+type PoolStats struct {
+	MaxOpenConnections int           // maximum number of open connections to the database
+	OpenConnections    int           // number of established connections, both in use and idle
+	InUse              int           // number of connections currently in use
+	Idle               int           // number of idle connections
+	WaitCount          int64         // total number of connections waited for
+	WaitDuration       time.Duration // total time blocked waiting for a new connection
+	MaxIdleClosed      int64         // total number of connections closed due to MaxIdleConns
+	MaxLifetimeClosed  int64         // total number of connections closed due to ConnMaxLifetime
+}
+
+// New connects to the database and configures the connection pool. gorm.Open pings the
+// database, so bad credentials or an unreachable host are reported here rather than on
+// the first query.
 func New(settings Settings) (DB, error) {
-	connectionString := getConnectionString(settings)
-	db, err := gorm.Open("postgres", connectionString)
+	db, err := connect(settings)
 	if err != nil {
 		return DB{}, err
 	}
 
-	d := DB{db: db}
+	sqlDB := db.DB()
+	if settings.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(settings.MaxOpenConns)
+	}
+	if settings.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(settings.MaxIdleConns)
+	}
+	if settings.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(settings.ConnMaxLifetime)
+	}
+
+	m := newMetrics(DB{db: db}.Stats)
+	registerMetricsCallbacks(db, m)
+	registerTracingCallbacks(db)
+
+	d := newDB(db, DB{metrics: m})
+	if settings.Geocoder != nil {
+		d = newDB(db, DB{metrics: m, geocoding: startGeocoding(d, settings.Geocoder)})
+	}
+	return d, nil
+}
+
+// newDB creates the providers for db, sharing from's metrics, geocoder and transaction. Providers
+// reach each other through parent, so they share whatever db is bound to, e.g. the context set by
+// WithContext.
+func newDB(db *gorm.DB, from DB) DB {
+	d := &DB{db: db, metrics: from.metrics, geocoding: from.geocoding, pending: from.pending}
 	d.Contacts = &ContactProvider{db: db, parent: d}
 	d.Addresses = &AddressProvider{db: db, parent: d}
+	d.Emails = &EmailProvider{db: db, parent: d}
+	d.Phones = &PhoneProvider{db: db, parent: d}
+	d.Tags = &TagProvider{db: db, parent: d}
+	d.Audit = &AuditProvider{db: db, parent: d}
+	return *d
+}
 
-	return d, nil
+// Stats returns the current connection pool statistics.
+func (d DB) Stats() PoolStats {
+	stats := d.db.DB().Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration,
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// Ping verifies the database is reachable.
+func (d DB) Ping(ctx context.Context) error {
+	return d.db.DB().PingContext(ctx)
+}
+
+// Close closes the database, waiting for in-flight queries and queued geocoding to finish.
+func (d DB) Close() error {
+	if d.geocoding != nil {
+		d.geocoding.stop()
+	}
+	return d.db.Close()
+}
+
+func connect(settings Settings) (*gorm.DB, error) {
+	connectionString := getConnectionString(settings)
+	backoff := settings.ConnectBackoff
+	if backoff < minConnectBackoff {
+		backoff = minConnectBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open("postgres", connectionString)
+		if err == nil {
+			return db, nil
+		}
+		if attempt > settings.ConnectRetries {
+			return nil, err
+		}
+
+		log.Warn("database connection failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff)
+	}
+}
+
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff < minConnectBackoff {
+		return minConnectBackoff
+	}
+	if backoff > maxConnectBackoff {
+		return maxConnectBackoff
+	}
+	return backoff
 }
 
 func getConnectionString(settings Settings) string {
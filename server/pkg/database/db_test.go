@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"testing"
+	"time"
 )
 
 var testSettings = Settings{
@@ -55,14 +56,57 @@ func TestNew_BadSettingsReturnsError(t *testing.T) {
 	}
 }
 
+func TestNew_RetriesBeforeReturningError(t *testing.T) {
+	// arrange
+	settings := Settings{
+		ConnectRetries: 2,
+		ConnectBackoff: 10 * time.Millisecond,
+	}
+
+	// act
+	start := time.Now()
+	_, err := New(settings)
+	elapsed := time.Since(start)
+
+	// assert
+	if err == nil {
+		t.Errorf("expected error, got <nil>")
+	}
+	// the backoff is raised to the 100ms minimum, so two retries wait 100ms then 200ms
+	if want := 300 * time.Millisecond; elapsed < want {
+		t.Errorf("elapsed, want: >= %v got: %v", want, elapsed)
+	}
+}
+
+func TestNextBackoff(t *testing.T) {
+	tests := []struct {
+		backoff time.Duration
+		want    time.Duration
+	}{
+		{0, minConnectBackoff},
+		{10 * time.Millisecond, minConnectBackoff},
+		{time.Second, 2 * time.Second},
+		{20 * time.Second, maxConnectBackoff},
+		{maxConnectBackoff, maxConnectBackoff},
+	}
+
+	for _, test := range tests {
+		if got := nextBackoff(test.backoff); got != test.want {
+			t.Errorf("nextBackoff(%v), want: %v got: %v", test.backoff, test.want, got)
+		}
+	}
+}
+
 func deleteAll() error {
 	db, err := New(testSettings)
 	if err != nil {
 		return err
 	}
 
-	if clone := db.db.Delete(Address{}); clone.Error != nil {
-		return clone.Error
+	for _, child := range []interface{}{Address{}, Email{}, Phone{}, Tag{}, AuditEntry{}} {
+		if clone := db.db.Delete(child); clone.Error != nil {
+			return clone.Error
+		}
 	}
 	if clone := db.db.Delete(Contact{}); clone.Error != nil {
 		return clone.Error
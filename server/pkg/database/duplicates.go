@@ -0,0 +1,229 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// MinNameSimilarity is the lowest name similarity FindDuplicates accepts, pg_trgm's default
+// similarity threshold, below which the trigram index can't be used.
+const MinNameSimilarity = 0.3
+
+// DuplicatePair is two contacts that may be the same person. Contacts are in ID order.
+type DuplicatePair struct {
+	Contacts       [2]Contact
+	NameSimilarity float64 // the trigram similarity of their full names, from 0 to 1
+	SimilarName    bool    // NameSimilarity reached the threshold
+	SharedAddress  bool
+	SharedEmail    bool
+}
+
+// childKeys are the SQL expressions, over a table alias, that identify the same address, email,
+// phone number or tag on two contacts.
+var childKeys = map[string]func(alias string) string{
+	"addresses": func(alias string) string {
+		return fmt.Sprintf("lower(%[1]s.line1) || '|' || lower(coalesce(%[1]s.line2, '')) || '|' || lower(%[1]s.city) || '|' || %[1]s.postal_code || '|' || %[1]s.country", alias)
+	},
+	"emails": func(alias string) string {
+		return fmt.Sprintf("lower(%s.address)", alias)
+	},
+	"phones": func(alias string) string {
+		return fmt.Sprintf("regexp_replace(%s.number, '[^0-9]', '', 'g')", alias)
+	},
+	"tags": func(alias string) string {
+		return fmt.Sprintf("lower(%s.name)", alias)
+	},
+}
+
+// contactColumns are the contacts columns in the order of contactFields.
+func contactColumns(alias string) string {
+	return fmt.Sprintf("%[1]s.id, %[1]s.first_name, %[1]s.last_name, %[1]s.company, %[1]s.title, %[1]s.notes, %[1]s.created_at, %[1]s.updated_at", alias)
+}
+
+// contactFields are the fields of c to scan contactColumns into.
+func contactFields(c *Contact) []interface{} {
+	return []interface{}{&c.ID, &c.FirstName, &c.LastName, &c.Company, &c.Title, &c.Notes, &c.CreatedAt, &c.UpdatedAt}
+}
+
+// FindDuplicates returns pairs of contacts whose names have a trigram similarity of at least
+// minSimilarity, or who share an address or an email address. Pairs with the most in common come
+// first, then the most similar names. At most limit pairs are returned.
+func (c ContactProvider) FindDuplicates(minSimilarity float64, limit int) (_ []DuplicatePair, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.FindDuplicates")
+	defer func() { endSpan(span, err) }()
+
+	if minSimilarity < MinNameSimilarity || minSimilarity > 1 {
+		return nil, &invalidRequest{"find duplicate contacts", fmt.Sprintf("minimum name similarity must be between %v and 1", MinNameSimilarity)}
+	}
+	if limit <= 0 {
+		return nil, &invalidRequest{"find duplicate contacts", "limit must be greater than 0"}
+	}
+
+	// parenthesized, since % binds tighter than ||
+	name := func(alias string) string { return "(" + alias + ".first_name || ' ' || " + alias + ".last_name)" }
+	query := `WITH pairs AS (
+			SELECT a.id AS first_id, b.id AS second_id, 'name' AS reason
+			FROM contacts a JOIN contacts b ON a.id < b.id
+				AND ` + name("a") + ` % ` + name("b") + `
+				AND similarity(` + name("a") + `, ` + name("b") + `) >= ?
+			UNION
+			SELECT x.contact_id, y.contact_id, 'address'
+			FROM addresses x JOIN addresses y ON x.contact_id < y.contact_id
+				AND ` + childKeys["addresses"]("x") + ` = ` + childKeys["addresses"]("y") + `
+			UNION
+			SELECT x.contact_id, y.contact_id, 'email'
+			FROM emails x JOIN emails y ON x.contact_id < y.contact_id
+				AND ` + childKeys["emails"]("x") + ` = ` + childKeys["emails"]("y") + `
+		)
+		SELECT ` + contactColumns("a") + `, ` + contactColumns("b") + `,
+			similarity(` + name("a") + `, ` + name("b") + `) AS name_similarity,
+			bool_or(pairs.reason = 'name'), bool_or(pairs.reason = 'address'), bool_or(pairs.reason = 'email')
+		FROM pairs
+		JOIN contacts a ON a.id = pairs.first_id
+		JOIN contacts b ON b.id = pairs.second_id
+		GROUP BY a.id, b.id
+		ORDER BY count(*) DESC, name_similarity DESC, a.id, b.id
+		LIMIT ?`
+
+	rows, err := traced.db.Raw(query, minSimilarity, limit).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := make([]DuplicatePair, 0)
+	for rows.Next() {
+		var p DuplicatePair
+		fields := append(contactFields(&p.Contacts[0]), contactFields(&p.Contacts[1])...)
+		fields = append(fields, &p.NameSimilarity, &p.SimilarName, &p.SharedAddress, &p.SharedEmail)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// mergeDetails are the details of a merge's audit entry.
+type mergeDetails struct {
+	SourceID        int              `json:"sourceId"`
+	SourceFirstName string           `json:"sourceFirstName"`
+	SourceLastName  string           `json:"sourceLastName"`
+	Moved           map[string]int64 `json:"moved"`   // rows moved to the target, by table
+	Dropped         map[string]int64 `json:"dropped"` // rows the target already had, by table
+}
+
+// Merge merges the source contact into the target in one transaction, and deletes the source.
+// The source's addresses, emails, phones and tags are moved to the target, except those the
+// target already has, and the target's empty company, title and notes are taken from the source.
+// The target keeps its primary address, email and phone if it has them. The merge is recorded in
+// the target's audit entries, after the entries for the target's update and the source's deletion.
+func (c ContactProvider) Merge(targetID, sourceID int) (_ Contact, err error) {
+	traced, span := c.parent.startSpan("ContactProvider.Merge")
+	defer func() { endSpan(span, err) }()
+
+	if targetID == sourceID {
+		return Contact{}, &invalidRequest{"merge contacts", "a contact can't be merged into itself"}
+	}
+
+	var target Contact
+	err = traced.Transaction(func(tx DB) error {
+		if target, err = tx.Contacts.Get(targetID); err != nil {
+			return err
+		}
+		source, err := tx.Contacts.Get(sourceID)
+		if err != nil {
+			return err
+		}
+
+		filled := target
+		filled.Company = firstNonNil(target.Company, source.Company)
+		filled.Title = firstNonNil(target.Title, source.Title)
+		filled.Notes = firstNonNil(target.Notes, source.Notes)
+		if filled != target {
+			if target, err = tx.Contacts.Update(filled); err != nil {
+				return err
+			}
+		}
+
+		details := mergeDetails{
+			SourceID:        source.ID,
+			SourceFirstName: source.FirstName,
+			SourceLastName:  source.LastName,
+			Moved:           make(map[string]int64),
+			Dropped:         make(map[string]int64),
+		}
+		for _, table := range []string{"addresses", "emails", "phones", "tags"} {
+			if table != "tags" {
+				if err := keepTargetPrimary(tx.db, table, targetID, sourceID); err != nil {
+					return err
+				}
+			}
+			moved, dropped, err := moveChildren(tx.db, table, targetID, sourceID)
+			if err != nil {
+				return err
+			}
+			details.Moved[table], details.Dropped[table] = moved, dropped
+		}
+
+		if err := tx.Contacts.Delete(sourceID); err != nil {
+			return err
+		}
+
+		detailsJSON, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		detailsString := string(detailsJSON)
+		_, err = tx.Audit.Create(AuditEntry{
+			Action:    AuditActionMerge,
+			Entity:    AuditEntityContact,
+			EntityID:  targetID,
+			ContactID: &targetID,
+			Details:   &detailsString,
+		})
+		return err
+	})
+	if err != nil {
+		return Contact{}, err
+	}
+	return target, nil
+}
+
+// moveChildren moves the source's rows of table to the target, first deleting those that duplicate
+// one of the target's. It returns how many rows were moved and deleted.
+func moveChildren(db *gorm.DB, table string, targetID, sourceID int) (moved, dropped int64, err error) {
+	key := childKeys[table]
+
+	deleted := db.Exec(`DELETE FROM `+table+` AS s USING `+table+` AS t
+		WHERE s.contact_id = ? AND t.contact_id = ? AND `+key("s")+` = `+key("t"), sourceID, targetID)
+	if deleted.Error != nil {
+		return 0, 0, deleted.Error
+	}
+
+	updated := db.Exec(`UPDATE `+table+` SET contact_id = ?, updated_at = ? WHERE contact_id = ?`, targetID, time.Now(), sourceID)
+	if updated.Error != nil {
+		return 0, 0, updated.Error
+	}
+	return updated.RowsAffected, deleted.RowsAffected, nil
+}
+
+// keepTargetPrimary clears the source's primary flag in table if the target has a primary, since a
+// contact may only have one.
+func keepTargetPrimary(db *gorm.DB, table string, targetID, sourceID int) error {
+	return db.Exec(`UPDATE `+table+` SET is_primary = false
+		WHERE contact_id = ? AND is_primary
+		AND EXISTS (SELECT 1 FROM `+table+` WHERE contact_id = ? AND is_primary)`, sourceID, targetID).Error
+}
+
+func firstNonNil(values ...*string) *string {
+	for _, v := range values {
+		if v != nil {
+			return v
+		}
+	}
+	return nil
+}
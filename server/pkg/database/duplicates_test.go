@@ -0,0 +1,193 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContactProvider_FindDuplicates(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	john, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jon, err := db.Contacts.Create(Contact{FirstName: "Jon", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	johnny, err := db.Contacts.Create(Contact{FirstName: "Johnny", LastName: "Appleseed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contactID := range []int{jon.ID, johnny.ID} {
+		if _, err = db.Emails.Create(Email{ContactID: contactID, Type: EmailTypeHome, Address: "john@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.Contacts.Create(Contact{FirstName: "Ada", LastName: "Lovelace"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	pairs, err := db.Contacts.FindDuplicates(0.5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(pairs) != 2 {
+		t.Fatalf("pairs, want: 2 got: %+v", pairs)
+	}
+	if pairs[0].Contacts[0].ID != john.ID || pairs[0].Contacts[1].ID != jon.ID || !pairs[0].SimilarName || pairs[0].SharedEmail {
+		t.Errorf("first pair, want: John and Jon by name got: %+v", pairs[0])
+	}
+	if pairs[1].Contacts[0].ID != jon.ID || pairs[1].Contacts[1].ID != johnny.ID || pairs[1].SimilarName || !pairs[1].SharedEmail {
+		t.Errorf("second pair, want: Jon and Johnny by email got: %+v", pairs[1])
+	}
+}
+
+func TestContactProvider_FindDuplicatesRejectsLowSimilarity(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Contacts.FindDuplicates(0.1, 10)
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+}
+
+func TestContactProvider_Merge(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	company := "Acme Corp."
+	target, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := db.Contacts.Create(Contact{FirstName: "Jon", LastName: "Doe", Company: &company})
+	if err != nil {
+		t.Fatal(err)
+	}
+	springfield := Address{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"}
+	for _, contactID := range []int{target.ID, source.ID} {
+		address := springfield
+		address.ContactID = contactID
+		if _, err = db.Addresses.Create(address); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = db.Emails.Create(Email{ContactID: contactID, Type: EmailTypeHome, Address: "john@example.com", IsPrimary: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = db.Addresses.Create(Address{ContactID: source.ID, Line1: "2 Main St", City: "Shelbyville", StateProvince: "IL", PostalCode: "62565"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = db.Emails.Create(Email{ContactID: source.ID, Type: EmailTypeWork, Address: "jon@acme.example", IsPrimary: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	merged, err := db.Contacts.Merge(target.ID, source.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if merged.Company == nil || *merged.Company != company {
+		t.Errorf("company, want: %q got: %v", company, merged.Company)
+	}
+	if _, err := db.Contacts.Get(source.ID); !IsNotFound(err) {
+		t.Errorf("source, want: deleted got: %v", err)
+	}
+	addresses, _ := db.Addresses.GetAllByContactID(target.ID)
+	if len(addresses) != 2 {
+		t.Errorf("addresses, want: 2 got: %+v", addresses)
+	}
+	emails, _ := db.Emails.GetAllByContactID(target.ID)
+	if len(emails) != 2 || emails[0].Address != "john@example.com" || !emails[0].IsPrimary || emails[1].IsPrimary {
+		t.Errorf("emails, want: john@example.com primary and jon@acme.example got: %+v", emails)
+	}
+
+	entries, err := db.Audit.GetAllByEntity(AuditEntityContact, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the target's create and update come first
+	if len(entries) != 3 || entries[2].Action != AuditActionMerge || entries[2].Details == nil {
+		t.Fatalf("audit entries, want: create, update and merge got: %+v", entries)
+	}
+	var details mergeDetails
+	if err := json.Unmarshal([]byte(*entries[2].Details), &details); err != nil {
+		t.Fatal(err)
+	}
+	if details.SourceID != source.ID || details.Moved["addresses"] != 1 || details.Dropped["addresses"] != 1 {
+		t.Errorf("audit details, want: source %d, 1 address moved and 1 dropped got: %+v", source.ID, details)
+	}
+}
+
+func TestContactProvider_MergeRollsBackWhenSourceIsMissing(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Contacts.Merge(target.ID, target.ID+1000)
+
+	// assert
+	if !IsNotFound(err) {
+		t.Errorf("err, want: not found got: %v", err)
+	}
+	if entries, _ := db.Audit.GetAllByEntity(AuditEntityContact, target.ID); len(entries) != 0 {
+		t.Errorf("audit entries, want: none got: %+v", entries)
+	}
+}
+
+func TestContactProvider_MergeIntoItselfIsInvalid(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Contacts.Merge(1, 1)
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package database
+
+import "github.com/jinzhu/gorm"
+
+type EmailProvider struct {
+	db     *gorm.DB
+	parent *DB
+}
+
+func (e EmailProvider) Create(email Email) (_ Email, err error) {
+	traced, span := e.parent.startSpan("EmailProvider.Create")
+	defer func() { endSpan(span, err) }()
+
+	if email.ID != 0 {
+		return Email{}, &invalidRequest{"create email", "id must be 0"}
+	}
+	if err := validateEmail("create email", email); err != nil {
+		return Email{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		if _, err := tx.Contacts.Get(email.ContactID); err != nil {
+			return err
+		}
+		if email.IsPrimary {
+			if err := clearPrimary(tx.db, "emails", email.ContactID, 0); err != nil {
+				return err
+			}
+		}
+		return tx.db.Create(&email).Error
+	})
+	if err != nil {
+		return Email{}, err
+	}
+	return email, nil
+}
+
+func (e EmailProvider) Get(id int) (_ Email, err error) {
+	traced, span := e.parent.startSpan("EmailProvider.Get")
+	defer func() { endSpan(span, err) }()
+
+	// gorm ignores a zero primary key on the struct, so filter by id explicitly
+	var email Email
+	if db := traced.db.Where("id = ?", id).Take(&email); db.Error != nil {
+		if IsNotFound(db.Error) {
+			return Email{}, &recordNotFound{"get email", id}
+		}
+		return Email{}, db.Error
+	}
+	return email, nil
+}
+
+func (e EmailProvider) GetAllByContactID(contactID int) (_ []Email, err error) {
+	traced, span := e.parent.startSpan("EmailProvider.GetAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	emails := make([]Email, 0)
+	if db := traced.db.Order("is_primary desc, id").Where(Email{ContactID: contactID}).Find(&emails); db.Error != nil {
+		return nil, db.Error
+	}
+	return emails, nil
+}
+
+func (e EmailProvider) Update(email Email) (_ Email, err error) {
+	traced, span := e.parent.startSpan("EmailProvider.Update")
+	defer func() { endSpan(span, err) }()
+
+	if err := validateEmail("update email", email); err != nil {
+		return Email{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		existing, err := tx.Emails.Get(email.ID)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"update email", email.ID}
+			}
+			return err
+		}
+
+		if email.CreatedAt.IsZero() {
+			email.CreatedAt = existing.CreatedAt
+		}
+
+		if email.IsPrimary {
+			if err := clearPrimary(tx.db, "emails", email.ContactID, email.ID); err != nil {
+				return err
+			}
+		}
+		return tx.db.Save(&email).Error
+	})
+	if err != nil {
+		return Email{}, err
+	}
+	return email, nil
+}
+
+func (e EmailProvider) Delete(id int) (err error) {
+	traced, span := e.parent.startSpan("EmailProvider.Delete")
+	defer func() { endSpan(span, err) }()
+
+	db := traced.db.Delete(&Email{ID: id})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return &recordNotFound{"delete email", id}
+	}
+	return nil
+}
+
+func (e EmailProvider) DeleteAllByContactID(contactID int) (err error) {
+	traced, span := e.parent.startSpan("EmailProvider.DeleteAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return err
+	}
+
+	// gorm only uses the primary key of the value passed to Delete, so the contact has to be
+	// filtered with Where or every email would be deleted
+	db := traced.db.Where("contact_id = ?", contactID).Delete(&Email{})
+	if db.Error != nil {
+		return db.Error
+	}
+	return nil
+}
@@ -1,21 +1,25 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/jinzhu/gorm"
 )
 
+// IsNotFound reports whether err, or an error it wraps, is a missing record.
 func IsNotFound(err error) bool {
-	if _, ok := err.(*recordNotFound); ok {
+	var notFound *recordNotFound
+	if errors.As(err, &notFound) {
 		return true
 	}
-	return gorm.IsRecordNotFoundError(err)
+	return gorm.IsRecordNotFoundError(err) || errors.Is(err, gorm.ErrRecordNotFound)
 }
 
+// IsInvalidRequest reports whether err, or an error it wraps, is a request the database refused.
 func IsInvalidRequest(err error) bool {
-	_, ok := err.(*invalidRequest)
-	return ok
+	var invalid *invalidRequest
+	return errors.As(err, &invalid)
 }
 
 type recordNotFound struct {
@@ -0,0 +1,38 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+func TestIsNotFound_Wrapped(t *testing.T) {
+	tests := []error{
+		&recordNotFound{"get contact", 1},
+		fmt.Errorf("row 2: %w", &recordNotFound{"get contact", 1}),
+		fmt.Errorf("row 2: %w", gorm.ErrRecordNotFound),
+	}
+
+	for _, err := range tests {
+		if !IsNotFound(err) {
+			t.Errorf("IsNotFound(%v), want: true got: false", err)
+		}
+	}
+}
+
+func TestIsInvalidRequest_Wrapped(t *testing.T) {
+	// arrange
+	err := fmt.Errorf("row 2: %w", &invalidRequest{"create contact", "id must be 0"})
+
+	// act
+	got := IsInvalidRequest(err)
+
+	// assert
+	if !got {
+		t.Errorf("IsInvalidRequest(%v), want: true got: false", err)
+	}
+	if IsNotFound(err) {
+		t.Errorf("IsNotFound(%v), want: false got: true", err)
+	}
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/geocode"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// geocodeQueueSize is how many addresses can wait for the geocoder before more are dropped.
+	geocodeQueueSize = 1000
+	// geocodeTimeout bounds a single Geocoder call.
+	geocodeTimeout = 10 * time.Second
+)
+
+// geocoding runs a Geocoder over saved addresses in the background, one at a time, so saving an
+// address never waits for it.
+type geocoding struct {
+	db       DB
+	geocoder geocode.Geocoder
+	queue    chan int
+	done     chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func startGeocoding(db DB, geocoder geocode.Geocoder) *geocoding {
+	g := &geocoding{
+		db:       db,
+		geocoder: geocoder,
+		queue:    make(chan int, geocodeQueueSize),
+		done:     make(chan struct{}),
+	}
+	go g.run()
+	return g
+}
+
+// geocodeLater queues the address for the geocoder, if there is one, once it's been committed.
+func (d DB) geocodeLater(id int) {
+	if d.geocoding == nil {
+		return
+	}
+	d.afterCommit(func() { d.geocoding.enqueue(id) })
+}
+
+// enqueue queues the address. If the queue is full the address is left without coordinates rather
+// than slowing down the request that saved it.
+func (g *geocoding) enqueue(id int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.closed {
+		return
+	}
+	select {
+	case g.queue <- id:
+	default:
+		log.Warn("geocoding queue is full, address left without coordinates", zap.Int("address_id", id))
+	}
+}
+
+// stop geocodes the addresses already queued and waits for that to finish.
+func (g *geocoding) stop() {
+	g.mu.Lock()
+	if !g.closed {
+		g.closed = true
+		close(g.queue)
+	}
+	g.mu.Unlock()
+
+	<-g.done
+}
+
+func (g *geocoding) run() {
+	defer close(g.done)
+
+	for id := range g.queue {
+		if err := g.geocode(id); err != nil {
+			log.Warn("geocoding address failed", zap.Int("address_id", id), zap.Error(err))
+		}
+	}
+}
+
+// geocode stores the coordinates of the address. The address may have changed or been deleted
+// since it was queued, so the coordinates are only stored if it's still as it was read; a change
+// queues it again.
+func (g *geocoding) geocode(id int) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), geocodeTimeout)
+	defer cancel()
+
+	db := g.db.WithContext(ctx)
+	traced, span := db.startSpan("geocoding.geocode")
+	defer func() { endSpan(span, err) }()
+
+	address, err := traced.Addresses.Get(id)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	location, err := g.geocoder.Geocode(ctx, postalAddress(address))
+	if errors.Is(err, geocode.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return traced.db.Model(&Address{}).
+		Where("id = ? AND updated_at = ?", address.ID, address.UpdatedAt).
+		UpdateColumns(map[string]interface{}{"lat": location.Lat, "lng": location.Lng}).
+		Error
+}
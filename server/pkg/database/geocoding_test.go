@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/geocode"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// fixedGeocoder places every address at the same location.
+type fixedGeocoder geocode.Location
+
+func (f fixedGeocoder) Geocode(context.Context, postal.Address) (geocode.Location, error) {
+	return geocode.Location(f), nil
+}
+
+func TestDB_AfterCommitRunsStraightAwayOutsideTransaction(t *testing.T) {
+	// arrange
+	ran := false
+
+	// act
+	DB{}.afterCommit(func() { ran = true })
+
+	// assert
+	if !ran {
+		t.Errorf("ran, want: true got: false")
+	}
+}
+
+func TestGeocoding_EnqueueDropsAddressesWhenQueueIsFull(t *testing.T) {
+	// arrange
+	g := &geocoding{queue: make(chan int, 1)}
+
+	// act
+	g.enqueue(1)
+	g.enqueue(2) // mustn't block
+
+	// assert
+	if got := <-g.queue; got != 1 {
+		t.Errorf("queued, want: 1 got: %d", got)
+	}
+	if len(g.queue) != 0 {
+		t.Errorf("queue length, want: 0 got: %d", len(g.queue))
+	}
+}
+
+func TestAddressProvider_CreateGeocodesInBackground(t *testing.T) {
+	// arrange
+	settings := testSettings
+	settings.Geocoder = fixedGeocoder{Lat: 39.8017, Lng: -89.6440}
+	db, err := New(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	address, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Close waits for the queue to drain
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	db, err = New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	geocoded, err := db.Addresses.Get(address.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if geocoded.Lat == nil || geocoded.Lng == nil || *geocoded.Lat != 39.8017 || *geocoded.Lng != -89.6440 {
+		t.Errorf("coordinates, want: 39.8017,-89.6440 got: %v,%v", geocoded.Lat, geocoded.Lng)
+	}
+}
+
+func TestAddressProvider_UpdateClearsCoordinatesWhenAddressMoves(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	contact, err := db.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lng := 39.8017, -89.6440
+	address, err := db.Addresses.Create(Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701", Lat: &lat, Lng: &lng})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	address.Lat, address.Lng = nil, nil
+	unmoved, err := db.Addresses.Update(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := unmoved
+	unmoved.Lat, unmoved.Lng = nil, nil
+	unmoved.PostalCode = "62702"
+	moved, err := db.Addresses.Update(unmoved)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if kept.Lat == nil || kept.Lng == nil || *kept.Lat != lat || *kept.Lng != lng {
+		t.Errorf("coordinates when unmoved, want: %v,%v got: %v,%v", lat, lng, kept.Lat, kept.Lng)
+	}
+	if moved.Lat != nil || moved.Lng != nil {
+		t.Errorf("coordinates after moving, want: nil got: %v,%v", moved.Lat, moved.Lng)
+	}
+}
+
+func TestContactProvider_NearReturnsNearestAddressPerContact(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(firstName string, locations ...[2]float64) Contact {
+		contact, err := db.Contacts.Create(Contact{FirstName: firstName, LastName: "Doe"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, location := range locations {
+			lat, lng := location[0], location[1]
+			address := Address{ContactID: contact.ID, Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701", Lat: &lat, Lng: &lng}
+			if _, err := db.Addresses.Create(address); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return contact
+	}
+	// Springfield, IL; Chicago is about 280 km away
+	jane := create("Jane", [2]float64{41.8858, -87.6181}, [2]float64{39.8017, -89.6440})
+	john := create("John", [2]float64{39.8200, -89.6500})
+	create("Jim", [2]float64{41.8858, -87.6181})
+	create("Joan")
+
+	// act
+	nearby, err := db.Contacts.Near(39.8017, -89.6440, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if len(nearby) != 2 || nearby[0].Contact.ID != jane.ID || nearby[1].Contact.ID != john.ID {
+		t.Fatalf("contacts, want: [%d %d] got: %+v", jane.ID, john.ID, nearby)
+	}
+	if nearby[0].Distance > 0.001 || *nearby[0].Address.Lat != 39.8017 {
+		t.Errorf("Jane's address, want: the one in Springfield got: %+v at %v km", nearby[0].Address, nearby[0].Distance)
+	}
+	// 0.0183 degrees of latitude and 0.006 of longitude
+	if nearby[1].Distance < 2 || nearby[1].Distance > 2.3 {
+		t.Errorf("John's distance, want: about 2.1 km got: %v", nearby[1].Distance)
+	}
+}
+
+func TestContactProvider_NearRejectsInvalidPoint(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = db.Contacts.Near(91, 0, 10)
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("err, want: invalid request got: %v", err)
+	}
+}
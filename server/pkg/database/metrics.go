@@ -0,0 +1,124 @@
+package database
+
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsStartKey = "metrics:start"
+
+// metrics holds the Prometheus collectors for a DB. They aren't registered anywhere by this
+// package; the caller decides which registry to add them to, see DB.Collectors.
+type metrics struct {
+	queryDuration *prometheus.HistogramVec
+	pool          *poolCollector
+}
+
+func newMetrics(stats func() PoolStats) *metrics {
+	return &metrics{
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of SQL statements executed through gorm.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "table", "status"}),
+		pool: &poolCollector{stats: stats},
+	}
+}
+
+// Collectors returns the database's Prometheus collectors: query duration by operation and table,
+// and connection pool gauges. A DB that wasn't opened with New has none.
+func (d DB) Collectors() []prometheus.Collector {
+	if d.metrics == nil {
+		return nil
+	}
+	return []prometheus.Collector{d.metrics.queryDuration, d.metrics.pool}
+}
+
+// registerMetricsCallbacks times the statement each gorm operation executes.
+func registerMetricsCallbacks(db *gorm.DB, m *metrics) {
+	callbacks := db.Callback()
+
+	callbacks.Create().Before("gorm:create").Register("metrics:before_create", startQueryTimer)
+	callbacks.Create().After("gorm:create").Register("metrics:after_create", m.observeQuery("create"))
+	callbacks.Query().Before("gorm:query").Register("metrics:before_query", startQueryTimer)
+	callbacks.Query().After("gorm:query").Register("metrics:after_query", m.observeQuery("query"))
+	callbacks.Update().Before("gorm:update").Register("metrics:before_update", startQueryTimer)
+	callbacks.Update().After("gorm:update").Register("metrics:after_update", m.observeQuery("update"))
+	callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", startQueryTimer)
+	callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", m.observeQuery("delete"))
+	callbacks.RowQuery().Before("gorm:row_query").Register("metrics:before_row_query", startQueryTimer)
+	callbacks.RowQuery().After("gorm:row_query").Register("metrics:after_row_query", m.observeQuery("row_query"))
+}
+
+func startQueryTimer(scope *gorm.Scope) {
+	scope.Set(metricsStartKey, time.Now())
+}
+
+func (m *metrics) observeQuery(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		value, ok := scope.Get(metricsStartKey)
+		if !ok {
+			return
+		}
+		start := value.(time.Time)
+
+		// a missing record is an answer, not a failed query
+		status := "ok"
+		if scope.HasError() && !gorm.IsRecordNotFoundError(scope.DB().Error) {
+			status = "error"
+		}
+
+		m.queryDuration.WithLabelValues(operation, scope.TableName(), status).Observe(time.Since(start).Seconds())
+	}
+}
+
+var (
+	poolMaxOpenDesc = prometheus.NewDesc("db_pool_max_open_connections",
+		"Maximum number of open connections to the database.", nil, nil)
+	poolOpenDesc = prometheus.NewDesc("db_pool_open_connections",
+		"Number of established connections, both in use and idle.", nil, nil)
+	poolInUseDesc = prometheus.NewDesc("db_pool_in_use_connections",
+		"Number of connections currently in use.", nil, nil)
+	poolIdleDesc = prometheus.NewDesc("db_pool_idle_connections",
+		"Number of idle connections.", nil, nil)
+	poolWaitCountDesc = prometheus.NewDesc("db_pool_wait_count_total",
+		"Total number of connections waited for.", nil, nil)
+	poolWaitDurationDesc = prometheus.NewDesc("db_pool_wait_duration_seconds_total",
+		"Total time blocked waiting for a new connection.", nil, nil)
+	poolMaxIdleClosedDesc = prometheus.NewDesc("db_pool_max_idle_closed_total",
+		"Total number of connections closed due to the idle connection limit.", nil, nil)
+	poolMaxLifetimeClosedDesc = prometheus.NewDesc("db_pool_max_lifetime_closed_total",
+		"Total number of connections closed due to the connection lifetime limit.", nil, nil)
+)
+
+// poolCollector reports DB.Stats each time the metrics are scraped.
+type poolCollector struct {
+	stats func() PoolStats
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolMaxOpenDesc
+	ch <- poolOpenDesc
+	ch <- poolInUseDesc
+	ch <- poolIdleDesc
+	ch <- poolWaitCountDesc
+	ch <- poolWaitDurationDesc
+	ch <- poolMaxIdleClosedDesc
+	ch <- poolMaxLifetimeClosedDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+
+	ch <- prometheus.MustNewConstMetric(poolMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(poolMaxIdleClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(poolMaxLifetimeClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
@@ -0,0 +1,372 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/log"
+	"go.uber.org/zap"
+)
+
+// migration is a schema change applied in a single transaction. Migrations are applied in
+// version order and recorded in the schema_migrations table; never edit one that has shipped,
+// add a new one instead.
+type migration struct {
+	version     int
+	description string
+	statements  []string
+	check       func(tx *gorm.DB) error // optional, run before the statements
+}
+
+// migrations are written with IF NOT EXISTS where possible so they can be applied to databases
+// that were created by hand from the readme.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create contacts and addresses",
+		statements: []string{
+			`CREATE TABLE IF NOT EXISTS contacts (
+				id         SERIAL PRIMARY KEY,
+				first_name VARCHAR(100) NOT NULL,
+				last_name  VARCHAR(100) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS addresses (
+				id             SERIAL PRIMARY KEY,
+				contact_id     INT NOT NULL,
+				line1          VARCHAR(100) NOT NULL,
+				line2          VARCHAR(100) NULL,
+				city           VARCHAR(50) NOT NULL,
+				state_province VARCHAR(50) NOT NULL,
+				postal_code    VARCHAR(50) NOT NULL,
+				created_at     TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at     TIMESTAMP WITH TIME ZONE NULL,
+				CONSTRAINT fk_addresses_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id)
+			)`,
+		},
+	},
+	{
+		version:     2,
+		description: "add contact details, emails, phones and tags",
+		statements: []string{
+			`ALTER TABLE contacts
+				ADD COLUMN IF NOT EXISTS company VARCHAR(100) NULL,
+				ADD COLUMN IF NOT EXISTS title   VARCHAR(100) NULL,
+				ADD COLUMN IF NOT EXISTS notes   TEXT NULL`,
+			`CREATE TABLE IF NOT EXISTS emails (
+				id         SERIAL PRIMARY KEY,
+				contact_id INT NOT NULL,
+				type       VARCHAR(10) NOT NULL,
+				address    VARCHAR(254) NOT NULL,
+				is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NULL,
+				CONSTRAINT fk_emails_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS emails_one_primary_per_contact ON emails (contact_id) WHERE is_primary`,
+			`CREATE TABLE IF NOT EXISTS phones (
+				id         SERIAL PRIMARY KEY,
+				contact_id INT NOT NULL,
+				type       VARCHAR(10) NOT NULL,
+				number     VARCHAR(50) NOT NULL,
+				is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NULL,
+				CONSTRAINT fk_phones_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS phones_one_primary_per_contact ON phones (contact_id) WHERE is_primary`,
+			`CREATE TABLE IF NOT EXISTS tags (
+				id         SERIAL PRIMARY KEY,
+				contact_id INT NOT NULL,
+				name       VARCHAR(50) NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+				updated_at TIMESTAMP WITH TIME ZONE NULL,
+				CONSTRAINT fk_tags_contact_id FOREIGN KEY (contact_id) REFERENCES contacts(id)
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS tags_name_per_contact ON tags (contact_id, lower(name))`,
+		},
+	},
+	{
+		version:     3,
+		description: "add address country and use state codes in US addresses",
+		statements: []string{
+			`ALTER TABLE addresses ADD COLUMN IF NOT EXISTS country CHAR(2) NOT NULL DEFAULT 'US'`,
+			`UPDATE addresses SET state_province = states.code
+			FROM (VALUES
+				('ALABAMA', 'AL'),
+				('ALASKA', 'AK'),
+				('ARIZONA', 'AZ'),
+				('ARKANSAS', 'AR'),
+				('CALIFORNIA', 'CA'),
+				('COLORADO', 'CO'),
+				('CONNECTICUT', 'CT'),
+				('DELAWARE', 'DE'),
+				('DISTRICT OF COLUMBIA', 'DC'),
+				('FLORIDA', 'FL'),
+				('GEORGIA', 'GA'),
+				('HAWAII', 'HI'),
+				('IDAHO', 'ID'),
+				('ILLINOIS', 'IL'),
+				('INDIANA', 'IN'),
+				('IOWA', 'IA'),
+				('KANSAS', 'KS'),
+				('KENTUCKY', 'KY'),
+				('LOUISIANA', 'LA'),
+				('MAINE', 'ME'),
+				('MARYLAND', 'MD'),
+				('MASSACHUSETTS', 'MA'),
+				('MICHIGAN', 'MI'),
+				('MINNESOTA', 'MN'),
+				('MISSISSIPPI', 'MS'),
+				('MISSOURI', 'MO'),
+				('MONTANA', 'MT'),
+				('NEBRASKA', 'NE'),
+				('NEVADA', 'NV'),
+				('NEW HAMPSHIRE', 'NH'),
+				('NEW JERSEY', 'NJ'),
+				('NEW MEXICO', 'NM'),
+				('NEW YORK', 'NY'),
+				('NORTH CAROLINA', 'NC'),
+				('NORTH DAKOTA', 'ND'),
+				('OHIO', 'OH'),
+				('OKLAHOMA', 'OK'),
+				('OREGON', 'OR'),
+				('PENNSYLVANIA', 'PA'),
+				('RHODE ISLAND', 'RI'),
+				('SOUTH CAROLINA', 'SC'),
+				('SOUTH DAKOTA', 'SD'),
+				('TENNESSEE', 'TN'),
+				('TEXAS', 'TX'),
+				('UTAH', 'UT'),
+				('VERMONT', 'VT'),
+				('VIRGINIA', 'VA'),
+				('WASHINGTON', 'WA'),
+				('WEST VIRGINIA', 'WV'),
+				('WISCONSIN', 'WI'),
+				('WYOMING', 'WY'),
+				('AMERICAN SAMOA', 'AS'),
+				('GUAM', 'GU'),
+				('NORTHERN MARIANA ISLANDS', 'MP'),
+				('PUERTO RICO', 'PR'),
+				('U.S. VIRGIN ISLANDS', 'VI'),
+				('U.S. MINOR OUTLYING ISLANDS', 'UM'),
+				('ARMED FORCES AMERICAS', 'AA'),
+				('ARMED FORCES EUROPE', 'AE'),
+				('ARMED FORCES PACIFIC', 'AP')
+			) AS states (name, code)
+			WHERE addresses.country = 'US' AND upper(trim(addresses.state_province)) = states.name`,
+			`UPDATE addresses SET state_province = upper(trim(state_province))
+			WHERE country = 'US' AND length(trim(state_province)) = 2`,
+		},
+	},
+	{
+		version:     4,
+		description: "add address coordinates",
+		statements: []string{
+			`ALTER TABLE addresses ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION NULL`,
+			`ALTER TABLE addresses ADD COLUMN IF NOT EXISTS lng DOUBLE PRECISION NULL`,
+			// ContactProvider.Near narrows its search to a box of latitudes and longitudes first
+			`CREATE INDEX IF NOT EXISTS addresses_lat_lng ON addresses (lat, lng) WHERE lat IS NOT NULL`,
+		},
+	},
+	{
+		version:     5,
+		description: "index contact names by trigram and create audit entries",
+		check:       requireExtension("pg_trgm"),
+		statements: []string{
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			// ContactProvider.FindDuplicates compares names with the % operator, which this serves
+			`CREATE INDEX IF NOT EXISTS contacts_name_trgm ON contacts USING gin ((first_name || ' ' || last_name) gin_trgm_ops)`,
+			`CREATE TABLE IF NOT EXISTS audit_entries (
+				id         SERIAL PRIMARY KEY,
+				action     VARCHAR(50) NOT NULL,
+				entity     VARCHAR(50) NOT NULL,
+				entity_id  INT NOT NULL,
+				details    JSONB NOT NULL,
+				created_at TIMESTAMP WITH TIME ZONE NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS audit_entries_entity ON audit_entries (entity, entity_id)`,
+		},
+	},
+	{
+		version:     6,
+		description: "add primary addresses",
+		statements: []string{
+			`ALTER TABLE addresses ADD COLUMN IF NOT EXISTS is_primary BOOLEAN NOT NULL DEFAULT FALSE`,
+			// every contact's oldest address becomes its primary
+			`UPDATE addresses SET is_primary = TRUE
+			WHERE id IN (SELECT min(id) FROM addresses GROUP BY contact_id)
+			AND NOT EXISTS (SELECT 1 FROM addresses primaries WHERE primaries.contact_id = addresses.contact_id AND primaries.is_primary)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS addresses_one_primary_per_contact ON addresses (contact_id) WHERE is_primary`,
+		},
+	},
+	{
+		version:     7,
+		description: "audit every change to contacts and addresses",
+		statements: []string{
+			`ALTER TABLE audit_entries
+				ADD COLUMN IF NOT EXISTS contact_id INT NULL,
+				ADD COLUMN IF NOT EXISTS actor      VARCHAR(255) NOT NULL DEFAULT '',
+				ADD COLUMN IF NOT EXISTS request_id VARCHAR(128) NOT NULL DEFAULT '',
+				ADD COLUMN IF NOT EXISTS ip         VARCHAR(45) NOT NULL DEFAULT '',
+				ADD COLUMN IF NOT EXISTS before     JSONB NULL,
+				ADD COLUMN IF NOT EXISTS after      JSONB NULL,
+				ALTER COLUMN details DROP NOT NULL`,
+			// the merges recorded so far are all of the contact merged into
+			`UPDATE audit_entries SET contact_id = entity_id WHERE entity = 'contact' AND contact_id IS NULL`,
+			// a contact's history is read newest first, as is the whole log
+			`CREATE INDEX IF NOT EXISTS audit_entries_contact ON audit_entries (contact_id, id)`,
+			`CREATE INDEX IF NOT EXISTS audit_entries_actor ON audit_entries (actor, id) WHERE actor <> ''`,
+			`CREATE INDEX IF NOT EXISTS audit_entries_created_at ON audit_entries (created_at)`,
+		},
+	},
+}
+
+type schemaMigration struct {
+	Version     int `gorm:"primary_key;auto_increment:false"`
+	Description string
+	AppliedAt   time.Time
+}
+
+// Migrate applies any migrations that have not yet been recorded in schema_migrations.
+func (d DB) Migrate() error {
+	if err := d.createMigrationsTable(); err != nil {
+		return err
+	}
+
+	applied, err := d.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		log.Info("applying database migration",
+			zap.Int("version", m.version),
+			zap.String("description", m.description),
+		)
+
+		if err := d.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s): %v", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// PendingMigrations returns the versions of migrations that have not been applied. Its queries are
+// cancelled when ctx is done.
+func (d DB) PendingMigrations(ctx context.Context) ([]int, error) {
+	applied := make(map[int]bool)
+
+	var exists bool
+	if err := d.db.DB().QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		rows, err := d.db.DB().QueryContext(ctx, `SELECT version FROM schema_migrations`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			if err := rows.Scan(&version); err != nil {
+				return nil, err
+			}
+			applied[version] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	pending := make([]int, 0)
+	for _, m := range migrations {
+		if !applied[m.version] {
+			pending = append(pending, m.version)
+		}
+	}
+	return pending, nil
+}
+
+func (d DB) createMigrationsTable() error {
+	return d.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INT PRIMARY KEY,
+		description VARCHAR(200) NOT NULL,
+		applied_at  TIMESTAMP WITH TIME ZONE NOT NULL
+	)`).Error
+}
+
+func (d DB) appliedMigrations() (map[int]bool, error) {
+	var rows []schemaMigration
+	if db := d.db.Find(&rows); db.Error != nil {
+		return nil, db.Error
+	}
+
+	applied := make(map[int]bool, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = true
+	}
+	return applied, nil
+}
+
+func (d DB) applyMigration(m migration) error {
+	tx := d.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if m.check != nil {
+		if err := m.check(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, statement := range m.statements {
+		if err := tx.Exec(statement).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	record := schemaMigration{Version: m.version, Description: m.description, AppliedAt: time.Now()}
+	if err := tx.Create(&record).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// requireExtension fails with the step to take by hand when the extension isn't installed and the
+// user running the migrations can't create it. Creating an extension takes a superuser, or on
+// Postgres 13 and later the CREATE privilege on the database for a trusted one such as pg_trgm.
+func requireExtension(name string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		var installed, available, allowed bool
+		err := tx.Raw(`SELECT
+			EXISTS (SELECT 1 FROM pg_extension WHERE extname = ?),
+			EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = ?),
+			(SELECT rolsuper FROM pg_roles WHERE rolname = current_user)
+				OR (current_setting('server_version_num')::int >= 130000 AND has_database_privilege(current_database(), 'CREATE'))`,
+			name, name).Row().Scan(&installed, &available, &allowed)
+		switch {
+		case err != nil:
+			return err
+		case installed:
+			return nil
+		case !available:
+			return fmt.Errorf("the %s extension isn't available on the database server; install the server's contrib package", name)
+		case !allowed:
+			return fmt.Errorf("creating the %s extension takes a superuser on this server; have one run CREATE EXTENSION %s; in the database, then restart", name, name)
+		}
+		return nil
+	}
+}
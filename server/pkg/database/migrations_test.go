@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDB_MigrateLeavesNoPendingMigrations(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	// migrating twice must be a no-op the second time
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if err = db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	pending, err := db.PendingMigrations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending, want: [] got: %v", pending)
+	}
+}
@@ -4,22 +4,120 @@ import (
 	"time"
 )
 
+// Contact is a person in the address book. The json names are those of the audit log's before and
+// after values.
 type Contact struct {
+	ID        int       `json:"id"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	Company   *string   `json:"company"`
+	Title     *string   `json:"title"`
+	Notes     *string   `json:"notes"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// DefaultCountry is the country of addresses created without one, and of the addresses that
+// existed before addresses had a country.
+const DefaultCountry = "US"
+
+// Address is a contact's postal address. AddressProvider normalizes it for its Country, an ISO
+// 3166-1 alpha-2 code, see postal.Normalize. Lat and Lng are filled in by the geocoder after the
+// address is saved, and are nil until then or if it has no location for the address. A contact with
+// addresses has exactly one primary address, the one to mail to.
+type Address struct {
+	ID            int       `json:"id"`
+	ContactID     int       `json:"contactId"`
+	Line1         string    `json:"line1"`
+	Line2         *string   `json:"line2"`
+	City          string    `json:"city"`
+	StateProvince string    `json:"stateProvince"`
+	PostalCode    string    `json:"postalCode"`
+	Country       string    `json:"country"`
+	Lat           *float64  `json:"lat"`
+	Lng           *float64  `json:"lng"`
+	IsPrimary     bool      `json:"isPrimary"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// email types
+const (
+	EmailTypeWork  = "work"
+	EmailTypeHome  = "home"
+	EmailTypeOther = "other"
+)
+
+// Email is one of a contact's email addresses. A contact has at most one primary email.
+type Email struct {
 	ID        int
-	FirstName string
-	LastName  string
+	ContactID int
+	Type      string
+	Address   string
+	IsPrimary bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
-type Address struct {
-	ID            int
-	ContactID     int
-	Line1         string
-	Line2         *string
-	City          string
-	StateProvince string
-	PostalCode    string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+// phone types
+const (
+	PhoneTypeWork   = "work"
+	PhoneTypeHome   = "home"
+	PhoneTypeMobile = "mobile"
+	PhoneTypeOther  = "other"
+)
+
+// Phone is one of a contact's phone numbers. A contact has at most one primary phone.
+type Phone struct {
+	ID        int
+	ContactID int
+	Type      string
+	Number    string
+	IsPrimary bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Tag labels a contact. A contact's tag names are unique, ignoring case.
+type Tag struct {
+	ID        int
+	ContactID int
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// audit actions
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionMerge  = "merge"
+)
+
+// audited entities
+const (
+	AuditEntityContact = "contact"
+	AuditEntityAddress = "address"
+)
+
+// AuditEntry records a change to the data. ContactID is the contact the entity is or belongs to.
+// Actor, RequestID and IP are those of the request that made the change, and are empty for changes
+// made outside of one. Before and After are JSON objects of the entity's fields: Before is nil for a
+// create and After nil for a delete, and an update only includes the fields that changed. Details
+// is a JSON object describing changes that aren't to a single entity, e.g. two contacts being
+// merged.
+type AuditEntry struct {
+	ID        int
+	Action    string
+	Entity    string
+	EntityID  int
+	ContactID *int
+	Actor     string
+	RequestID string
+	IP        string `gorm:"column:ip"`
+	Before    *string
+	After     *string
+	Details   *string
+	CreatedAt time.Time
 }
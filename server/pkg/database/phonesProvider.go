@@ -0,0 +1,133 @@
+package database
+
+import "github.com/jinzhu/gorm"
+
+type PhoneProvider struct {
+	db     *gorm.DB
+	parent *DB
+}
+
+func (p PhoneProvider) Create(phone Phone) (_ Phone, err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.Create")
+	defer func() { endSpan(span, err) }()
+
+	if phone.ID != 0 {
+		return Phone{}, &invalidRequest{"create phone", "id must be 0"}
+	}
+	if err := validatePhone("create phone", phone); err != nil {
+		return Phone{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		if _, err := tx.Contacts.Get(phone.ContactID); err != nil {
+			return err
+		}
+		if phone.IsPrimary {
+			if err := clearPrimary(tx.db, "phones", phone.ContactID, 0); err != nil {
+				return err
+			}
+		}
+		return tx.db.Create(&phone).Error
+	})
+	if err != nil {
+		return Phone{}, err
+	}
+	return phone, nil
+}
+
+func (p PhoneProvider) Get(id int) (_ Phone, err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.Get")
+	defer func() { endSpan(span, err) }()
+
+	// gorm ignores a zero primary key on the struct, so filter by id explicitly
+	var phone Phone
+	if db := traced.db.Where("id = ?", id).Take(&phone); db.Error != nil {
+		if IsNotFound(db.Error) {
+			return Phone{}, &recordNotFound{"get phone", id}
+		}
+		return Phone{}, db.Error
+	}
+	return phone, nil
+}
+
+func (p PhoneProvider) GetAllByContactID(contactID int) (_ []Phone, err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.GetAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	phones := make([]Phone, 0)
+	if db := traced.db.Order("is_primary desc, id").Where(Phone{ContactID: contactID}).Find(&phones); db.Error != nil {
+		return nil, db.Error
+	}
+	return phones, nil
+}
+
+func (p PhoneProvider) Update(phone Phone) (_ Phone, err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.Update")
+	defer func() { endSpan(span, err) }()
+
+	if err := validatePhone("update phone", phone); err != nil {
+		return Phone{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		existing, err := tx.Phones.Get(phone.ID)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"update phone", phone.ID}
+			}
+			return err
+		}
+
+		if phone.CreatedAt.IsZero() {
+			phone.CreatedAt = existing.CreatedAt
+		}
+
+		if phone.IsPrimary {
+			if err := clearPrimary(tx.db, "phones", phone.ContactID, phone.ID); err != nil {
+				return err
+			}
+		}
+		return tx.db.Save(&phone).Error
+	})
+	if err != nil {
+		return Phone{}, err
+	}
+	return phone, nil
+}
+
+func (p PhoneProvider) Delete(id int) (err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.Delete")
+	defer func() { endSpan(span, err) }()
+
+	db := traced.db.Delete(&Phone{ID: id})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return &recordNotFound{"delete phone", id}
+	}
+	return nil
+}
+
+func (p PhoneProvider) DeleteAllByContactID(contactID int) (err error) {
+	traced, span := p.parent.startSpan("PhoneProvider.DeleteAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return err
+	}
+
+	// gorm only uses the primary key of the value passed to Delete, so the contact has to be
+	// filtered with Where or every phone would be deleted
+	db := traced.db.Where("contact_id = ?", contactID).Delete(&Phone{})
+	if db.Error != nil {
+		return db.Error
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/jinzhu/gorm"
+)
+
+type TagProvider struct {
+	db     *gorm.DB
+	parent *DB
+}
+
+func (t TagProvider) Create(tag Tag) (_ Tag, err error) {
+	traced, span := t.parent.startSpan("TagProvider.Create")
+	defer func() { endSpan(span, err) }()
+
+	if tag.ID != 0 {
+		return Tag{}, &invalidRequest{"create tag", "id must be 0"}
+	}
+	if err := validateTag("create tag", tag); err != nil {
+		return Tag{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		if _, err := tx.Contacts.Get(tag.ContactID); err != nil {
+			return err
+		}
+		if err := tx.Tags.ensureUnique(tag); err != nil {
+			return err
+		}
+		return tx.db.Create(&tag).Error
+	})
+	if err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
+func (t TagProvider) Get(id int) (_ Tag, err error) {
+	traced, span := t.parent.startSpan("TagProvider.Get")
+	defer func() { endSpan(span, err) }()
+
+	// gorm ignores a zero primary key on the struct, so filter by id explicitly
+	var tag Tag
+	if db := traced.db.Where("id = ?", id).Take(&tag); db.Error != nil {
+		if IsNotFound(db.Error) {
+			return Tag{}, &recordNotFound{"get tag", id}
+		}
+		return Tag{}, db.Error
+	}
+	return tag, nil
+}
+
+func (t TagProvider) GetAllByContactID(contactID int) (_ []Tag, err error) {
+	traced, span := t.parent.startSpan("TagProvider.GetAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, 0)
+	if db := traced.db.Order("lower(name)").Where(Tag{ContactID: contactID}).Find(&tags); db.Error != nil {
+		return nil, db.Error
+	}
+	return tags, nil
+}
+
+func (t TagProvider) Update(tag Tag) (_ Tag, err error) {
+	traced, span := t.parent.startSpan("TagProvider.Update")
+	defer func() { endSpan(span, err) }()
+
+	if err := validateTag("update tag", tag); err != nil {
+		return Tag{}, err
+	}
+
+	err = traced.Transaction(func(tx DB) error {
+		existing, err := tx.Tags.Get(tag.ID)
+		if err != nil {
+			if IsNotFound(err) {
+				return &recordNotFound{"update tag", tag.ID}
+			}
+			return err
+		}
+
+		if tag.CreatedAt.IsZero() {
+			tag.CreatedAt = existing.CreatedAt
+		}
+
+		if err := tx.Tags.ensureUnique(tag); err != nil {
+			return err
+		}
+		return tx.db.Save(&tag).Error
+	})
+	if err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
+func (t TagProvider) Delete(id int) (err error) {
+	traced, span := t.parent.startSpan("TagProvider.Delete")
+	defer func() { endSpan(span, err) }()
+
+	db := traced.db.Delete(&Tag{ID: id})
+	if db.Error != nil {
+		return db.Error
+	}
+	if db.RowsAffected == 0 {
+		return &recordNotFound{"delete tag", id}
+	}
+	return nil
+}
+
+func (t TagProvider) DeleteAllByContactID(contactID int) (err error) {
+	traced, span := t.parent.startSpan("TagProvider.DeleteAllByContactID")
+	defer func() { endSpan(span, err) }()
+
+	_, err = traced.Contacts.Get(contactID)
+	if err != nil {
+		return err
+	}
+
+	// gorm only uses the primary key of the value passed to Delete, so the contact has to be
+	// filtered with Where or every tag would be deleted
+	db := traced.db.Where("contact_id = ?", contactID).Delete(&Tag{})
+	if db.Error != nil {
+		return db.Error
+	}
+	return nil
+}
+
+// ensureUnique returns an invalid request if the contact has another tag with the same name,
+// ignoring case.
+func (t TagProvider) ensureUnique(tag Tag) error {
+	var count int
+	db := t.db.Model(&Tag{}).
+		Where("contact_id = ? AND id <> ? AND lower(name) = lower(?)", tag.ContactID, tag.ID, tag.Name).
+		Count(&count)
+	if db.Error != nil {
+		return db.Error
+	}
+	if count > 0 {
+		return &invalidRequest{"save tag", fmt.Sprintf("the contact already has a tag named %q", tag.Name)}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName = "github.com/vicesoftware/vice-go-boilerplate/pkg/database"
+
+	contextKey     = "tracing:context"
+	tracingSpanKey = "tracing:span"
+)
+
+// WithContext returns a copy of the DB whose providers run their queries under ctx, so provider
+// calls and SQL statements are traced as children of the caller's span.
+func (d DB) WithContext(ctx context.Context) DB {
+	return newDB(d.db.Set(contextKey, ctx), d)
+}
+
+// dbContext returns the context bound by WithContext, or context.Background if there isn't one.
+func dbContext(db *gorm.DB) context.Context {
+	if value, ok := db.Get(contextKey); ok {
+		if ctx, ok := value.(context.Context); ok {
+			return ctx
+		}
+	}
+	return context.Background()
+}
+
+// startSpan starts a span for a provider call and returns a DB bound to the span's context, so
+// nested provider calls and SQL statements are traced beneath it.
+func (d *DB) startSpan(name string) (DB, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(dbContext(d.db), name)
+	return d.WithContext(ctx), span
+}
+
+// endSpan records err, if any, and ends the span. A missing record is reported but doesn't mark
+// the span as failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !IsNotFound(err) && !IsInvalidRequest(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// registerTracingCallbacks creates a span for the statement each gorm operation executes.
+func registerTracingCallbacks(db *gorm.DB) {
+	callbacks := db.Callback()
+
+	callbacks.Create().Before("gorm:create").Register("tracing:before_create", startQuerySpan("INSERT"))
+	callbacks.Create().After("gorm:create").Register("tracing:after_create", endQuerySpan)
+	callbacks.Query().Before("gorm:query").Register("tracing:before_query", startQuerySpan("SELECT"))
+	callbacks.Query().After("gorm:query").Register("tracing:after_query", endQuerySpan)
+	callbacks.Update().Before("gorm:update").Register("tracing:before_update", startQuerySpan("UPDATE"))
+	callbacks.Update().After("gorm:update").Register("tracing:after_update", endQuerySpan)
+	callbacks.Delete().Before("gorm:delete").Register("tracing:before_delete", startQuerySpan("DELETE"))
+	callbacks.Delete().After("gorm:delete").Register("tracing:after_delete", endQuerySpan)
+	callbacks.RowQuery().Before("gorm:row_query").Register("tracing:before_row_query", startQuerySpan("SELECT"))
+	callbacks.RowQuery().After("gorm:row_query").Register("tracing:after_row_query", endQuerySpan)
+}
+
+func startQuerySpan(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		table := scope.TableName()
+		_, span := otel.Tracer(tracerName).Start(dbContext(scope.DB()), operation+" "+table,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemPostgreSQL,
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(table),
+			),
+		)
+		scope.Set(tracingSpanKey, span)
+	}
+}
+
+func endQuerySpan(scope *gorm.Scope) {
+	value, ok := scope.Get(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+
+	// the statement is only known once gorm has built it. values are bound as parameters, so the
+	// text doesn't include them.
+	span.SetAttributes(
+		semconv.DBQueryText(strings.TrimSpace(scope.SQL)),
+		attribute.Int64("db.rows_affected", scope.DB().RowsAffected),
+	)
+
+	if err := scope.DB().Error; err != nil && !gorm.IsRecordNotFoundError(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,57 @@
+package database
+
+import "database/sql"
+
+// Transaction runs fn with a DB whose providers all work in one database transaction. The
+// transaction is committed if fn returns nil and rolled back if it returns an error or panics.
+// Only use tx inside fn.
+//
+// Called on a DB that's already in a transaction, fn joins it rather than starting another, so an
+// error from fn rolls back the enclosing transaction when it's returned from there.
+func (d DB) Transaction(fn func(tx DB) error) (err error) {
+	traced, span := d.startSpan("DB.Transaction")
+	defer func() { endSpan(span, err) }()
+
+	if _, ok := traced.db.CommonDB().(*sql.Tx); ok {
+		return fn(traced)
+	}
+
+	db := traced.db.Begin()
+	if db.Error != nil {
+		return db.Error
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			db.Rollback()
+		}
+	}()
+
+	var pending []func()
+	inTransaction := traced
+	inTransaction.pending = &pending
+	if err := fn(newDB(db, inTransaction)); err != nil {
+		return err
+	}
+
+	if err := db.Commit().Error; err != nil {
+		return err
+	}
+	committed = true
+
+	for _, f := range pending {
+		f()
+	}
+	return nil
+}
+
+// afterCommit runs fn once d's transaction has committed, or straight away if d isn't in one. fn
+// never runs if the transaction rolls back.
+func (d DB) afterCommit(fn func()) {
+	if d.pending != nil {
+		*d.pending = append(*d.pending, fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDB_TransactionCommits(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	var created Contact
+	err = db.Transaction(func(tx DB) error {
+		created, err = tx.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if _, err := db.Contacts.Get(created.ID); err != nil {
+		t.Errorf("Get after commit, want: nil got: %v", err)
+	}
+}
+
+func TestDB_TransactionRollsBackOnError(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failure := errors.New("failure")
+
+	// act
+	var created Contact
+	err = db.Transaction(func(tx DB) error {
+		if created, err = tx.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"}); err != nil {
+			return err
+		}
+		return failure
+	})
+
+	// assert
+	if err != failure {
+		t.Errorf("err, want: %v got: %v", failure, err)
+	}
+	if _, err := db.Contacts.Get(created.ID); !IsNotFound(err) {
+		t.Errorf("Get after rollback, want: not found got: %v", err)
+	}
+}
+
+func TestDB_TransactionJoinsEnclosingTransaction(t *testing.T) {
+	// arrange
+	db, err := New(testSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	failure := errors.New("failure")
+
+	// act; the inner transaction succeeds but the outer one fails
+	var created Contact
+	err = db.Transaction(func(tx DB) error {
+		err := tx.Transaction(func(inner DB) (err error) {
+			created, err = inner.Contacts.Create(Contact{FirstName: "John", LastName: "Doe"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return failure
+	})
+
+	// assert
+	if err != failure {
+		t.Errorf("err, want: %v got: %v", failure, err)
+	}
+	if _, err := db.Contacts.Get(created.ID); !IsNotFound(err) {
+		t.Errorf("Get after rollback, want: not found got: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jinzhu/gorm"
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+var (
+	emailTypes = []string{EmailTypeWork, EmailTypeHome, EmailTypeOther}
+	phoneTypes = []string{PhoneTypeWork, PhoneTypeHome, PhoneTypeMobile, PhoneTypeOther}
+
+	// phoneNumber allows the usual punctuation and an optional leading + and extension, e.g.
+	// +1 (555) 010-9999 x12
+	phoneNumber = regexp.MustCompile(`^\+?\(?[0-9][0-9 ().\-]*( ?(x|ext\.?) ?[0-9]+)?$`)
+)
+
+// normalizeAddress returns address in the canonical form for its country, see postal.Normalize.
+func normalizeAddress(action string, address Address) (Address, error) {
+	normalized, err := postal.Normalize(postalAddress(address))
+	if err != nil {
+		return Address{}, &invalidRequest{action, err.Error()}
+	}
+
+	address.Line1 = normalized.Line1
+	address.Line2 = nil
+	if normalized.Line2 != "" {
+		address.Line2 = &normalized.Line2
+	}
+	address.City = normalized.City
+	address.StateProvince = normalized.StateProvince
+	address.PostalCode = normalized.PostalCode
+	address.Country = normalized.Country
+	return address, nil
+}
+
+func postalAddress(address Address) postal.Address {
+	line2 := ""
+	if address.Line2 != nil {
+		line2 = *address.Line2
+	}
+	return postal.Address{
+		Line1:         address.Line1,
+		Line2:         line2,
+		City:          address.City,
+		StateProvince: address.StateProvince,
+		PostalCode:    address.PostalCode,
+		Country:       address.Country,
+	}
+}
+
+func validateEmail(action string, email Email) error {
+	if err := validateType(action, email.Type, emailTypes); err != nil {
+		return err
+	}
+	if len(email.Address) > 254 {
+		return &invalidRequest{action, "address must be at most 254 characters"}
+	}
+	// only a bare address; ParseAddress also accepts a display name, e.g. "John <john@example.com>"
+	parsed, err := mail.ParseAddress(email.Address)
+	if err != nil || parsed.Address != email.Address {
+		return &invalidRequest{action, "address must be an email address such as john@example.com"}
+	}
+	return nil
+}
+
+func validatePhone(action string, phone Phone) error {
+	if err := validateType(action, phone.Type, phoneTypes); err != nil {
+		return err
+	}
+	if len(phone.Number) > 50 {
+		return &invalidRequest{action, "number must be at most 50 characters"}
+	}
+	if !phoneNumber.MatchString(phone.Number) {
+		return &invalidRequest{action, "number must be a phone number such as +1 555 010 9999"}
+	}
+	return nil
+}
+
+func validateTag(action string, tag Tag) error {
+	if strings.TrimSpace(tag.Name) != tag.Name || tag.Name == "" {
+		return &invalidRequest{action, "name must not be empty or start or end with spaces"}
+	}
+	if utf8.RuneCountInString(tag.Name) > 50 {
+		return &invalidRequest{action, "name must be at most 50 characters"}
+	}
+	return nil
+}
+
+func validateType(action, value string, allowed []string) error {
+	for _, t := range allowed {
+		if value == t {
+			return nil
+		}
+	}
+	return &invalidRequest{action, "type must be one of " + strings.Join(allowed, ", ")}
+}
+
+// clearPrimary unsets the primary flag on the contact's rows in table other than exceptID, so that
+// row can become primary without breaking the table's one primary per contact index.
+func clearPrimary(db *gorm.DB, table string, contactID, exceptID int) error {
+	return db.Table(table).
+		Where("contact_id = ? AND id <> ? AND is_primary", contactID, exceptID).
+		UpdateColumn("is_primary", false).Error
+}
+
+// lockContacts locks the contacts' rows until the transaction ends, so concurrent changes to their
+// primary addresses are made one at a time rather than both setting one. Rows are locked in ID
+// order so two transactions can't deadlock. A missing contact is reported as not found.
+func lockContacts(db *gorm.DB, action string, contactIDs ...int) error {
+	sort.Ints(contactIDs)
+	for _, id := range contactIDs {
+		var contact Contact
+		err := db.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).Take(&contact).Error
+		if IsNotFound(err) {
+			return &recordNotFound{action, id}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
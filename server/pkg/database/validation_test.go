@@ -0,0 +1,106 @@
+package database
+
+import "testing"
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email Email
+		valid bool
+	}{
+		{Email{Type: EmailTypeWork, Address: "john@example.com"}, true},
+		{Email{Type: EmailTypeHome, Address: "john.doe+crm@mail.example.co.uk"}, true},
+		{Email{Type: "mobile", Address: "john@example.com"}, false},
+		{Email{Type: EmailTypeWork, Address: "John <john@example.com>"}, false},
+		{Email{Type: EmailTypeWork, Address: "john"}, false},
+		{Email{Type: EmailTypeWork, Address: ""}, false},
+	}
+
+	for _, tt := range tests {
+		// act
+		err := validateEmail("create email", tt.email)
+
+		// assert
+		if (err == nil) != tt.valid {
+			t.Errorf("%+v: valid, want: %v got: %v (%v)", tt.email, tt.valid, err == nil, err)
+		}
+		if err != nil && !IsInvalidRequest(err) {
+			t.Errorf("%+v: error, want: invalid request got: %v", tt.email, err)
+		}
+	}
+}
+
+func TestValidatePhone(t *testing.T) {
+	tests := []struct {
+		phone Phone
+		valid bool
+	}{
+		{Phone{Type: PhoneTypeMobile, Number: "+1 (555) 010-9999"}, true},
+		{Phone{Type: PhoneTypeWork, Number: "555.010.9999 x12"}, true},
+		{Phone{Type: PhoneTypeWork, Number: "020 7946 0000 ext. 3"}, true},
+		{Phone{Type: "fax", Number: "5550109999"}, false},
+		{Phone{Type: PhoneTypeHome, Number: "call me"}, false},
+		{Phone{Type: PhoneTypeHome, Number: "(555) 010-9999"}, true},
+		{Phone{Type: PhoneTypeHome, Number: "+()"}, false},
+		{Phone{Type: PhoneTypeHome, Number: ""}, false},
+	}
+
+	for _, tt := range tests {
+		// act
+		err := validatePhone("create phone", tt.phone)
+
+		// assert
+		if (err == nil) != tt.valid {
+			t.Errorf("%+v: valid, want: %v got: %v (%v)", tt.phone, tt.valid, err == nil, err)
+		}
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"customer", true},
+		{"VIP lead", true},
+		{"", false},
+		{" customer", false},
+		{"ééééééééééééééééééééééééééééééééééééééééééééééééé", true},
+		{"ééééééééééééééééééééééééééééééééééééééééééééééééééé", false},
+	}
+
+	for _, tt := range tests {
+		// act
+		err := validateTag("create tag", Tag{Name: tt.name})
+
+		// assert
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: valid, want: %v got: %v (%v)", tt.name, tt.valid, err == nil, err)
+		}
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	// arrange
+	line2 := "  "
+	address := Address{Line1: " 1 Main St ", Line2: &line2, City: "Springfield", StateProvince: "illinois", PostalCode: "627011234", Country: "us"}
+
+	// act
+	normalized, err := normalizeAddress("create address", address)
+
+	// assert
+	if err != nil {
+		t.Fatalf("normalizeAddress error, want: nil got: %v", err)
+	}
+	want := Address{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "62701-1234", Country: "US"}
+	if normalized != want {
+		t.Errorf("normalizeAddress, want: %+v got: %+v", want, normalized)
+	}
+
+	// act
+	_, err = normalizeAddress("create address", Address{Line1: "1 Main St", City: "Springfield", StateProvince: "IL", PostalCode: "6270", Country: "US"})
+
+	// assert
+	if !IsInvalidRequest(err) {
+		t.Errorf("normalizeAddress error, want: invalid request got: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+// Package geocode finds the coordinates of postal addresses.
+package geocode
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// ErrNotFound is returned by a Geocoder that has no location for an address.
+var ErrNotFound = errors.New("geocode: no location for address")
+
+// Location is a point in WGS 84 degrees.
+type Location struct {
+	Lat float64
+	Lng float64
+}
+
+// Geocoder finds the location of an address normalized by postal.Normalize. Implementations may
+// call remote services, so they must be safe for concurrent use and honor ctx.
+type Geocoder interface {
+	Geocode(ctx context.Context, address postal.Address) (Location, error)
+}
@@ -0,0 +1,111 @@
+package geocode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+// centroids is a sample of postal code centroids in the GeoNames format, enough to try the
+// feature out. Production deployments should load a complete file with NewOffline.
+//
+//go:embed centroids.txt
+var centroids []byte
+
+// Offline geocodes addresses to the centroid of their postal code, from a table held in memory.
+// It only knows where postal codes are, not streets, so every address sharing a postal code gets
+// the same location.
+type Offline struct {
+	locations map[string]Location
+}
+
+// Bundled returns an Offline geocoder over the sample table compiled into the binary.
+func Bundled() (*Offline, error) {
+	return NewOffline(bytes.NewReader(centroids))
+}
+
+// NewOffline reads a table of postal code centroids in the tab-separated format of the GeoNames
+// postal code dumps (https://download.geonames.org/export/zip/): country code, postal code, place
+// name, three pairs of admin name and code, latitude and longitude, with anything after ignored.
+// Blank lines and lines starting with # are skipped. Postal codes listed more than once, for the
+// several places they serve, are placed at the average of their rows.
+func NewOffline(r io.Reader) (*Offline, error) {
+	type sum struct {
+		lat, lng float64
+		n        int
+	}
+	sums := make(map[string]*sum)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < 11 {
+			return nil, fmt.Errorf("line %d: want at least 11 tab-separated fields, got %d", line, len(fields))
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(fields[9]), 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("line %d: invalid latitude %q", line, fields[9])
+		}
+		lng, err := strconv.ParseFloat(strings.TrimSpace(fields[10]), 64)
+		if err != nil || lng < -180 || lng > 180 {
+			return nil, fmt.Errorf("line %d: invalid longitude %q", line, fields[10])
+		}
+
+		k := key(fields[0], fields[1])
+		s, ok := sums[k]
+		if !ok {
+			s = &sum{}
+			sums[k] = s
+		}
+		s.lat += lat
+		s.lng += lng
+		s.n++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	locations := make(map[string]Location, len(sums))
+	for k, s := range sums {
+		locations[k] = Location{Lat: s.lat / float64(s.n), Lng: s.lng / float64(s.n)}
+	}
+	return &Offline{locations: locations}, nil
+}
+
+// Len returns the number of postal codes in the table.
+func (o *Offline) Len() int {
+	return len(o.locations)
+}
+
+// Geocode returns the centroid of the address's postal code. Some countries' tables only list the
+// first part of their postal codes, e.g. the outward code of a British postcode or the first
+// three characters of a Canadian one, so if the whole code isn't found the part before the first
+// space or hyphen is tried, which also reduces a ZIP+4 code to its ZIP code.
+func (o *Offline) Geocode(_ context.Context, address postal.Address) (Location, error) {
+	if location, ok := o.locations[key(address.Country, address.PostalCode)]; ok {
+		return location, nil
+	}
+	if i := strings.IndexAny(address.PostalCode, " -"); i > 0 {
+		if location, ok := o.locations[key(address.Country, address.PostalCode[:i])]; ok {
+			return location, nil
+		}
+	}
+	return Location{}, ErrNotFound
+}
+
+// key identifies a postal code in a country, ignoring case and spaces.
+func key(country, postalCode string) string {
+	return strings.ToUpper(strings.TrimSpace(country)) + " " + strings.ToUpper(strings.Join(strings.Fields(postalCode), ""))
+}
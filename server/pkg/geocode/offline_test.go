@@ -0,0 +1,96 @@
+package geocode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vicesoftware/vice-go-boilerplate/pkg/postal"
+)
+
+func TestBundled_Geocode(t *testing.T) {
+	// arrange
+	geocoder, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		address postal.Address
+		want    Location
+	}{
+		{"ZIP code", postal.Address{PostalCode: "62701", Country: "US"}, Location{Lat: 39.8017, Lng: -89.6440}},
+		{"ZIP+4 code", postal.Address{PostalCode: "62701-1234", Country: "US"}, Location{Lat: 39.8017, Lng: -89.6440}},
+		{"British outward code", postal.Address{PostalCode: "SW1A 1AA", Country: "GB"}, Location{Lat: 51.5010, Lng: -0.1416}},
+		{"Canadian forward sortation area", postal.Address{PostalCode: "K1A 0B1", Country: "CA"}, Location{Lat: 45.4215, Lng: -75.6972}},
+	}
+
+	for _, tt := range tests {
+		// act
+		got, err := geocoder.Geocode(context.Background(), tt.address)
+
+		// assert
+		if err != nil {
+			t.Errorf("%s: Geocode error, want: nil got: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: Geocode, want: %+v got: %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestOffline_GeocodeUnknownPostalCode(t *testing.T) {
+	// arrange
+	geocoder, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err = geocoder.Geocode(context.Background(), postal.Address{PostalCode: "62701", Country: "DE"})
+
+	// assert
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Geocode error, want: %v got: %v", ErrNotFound, err)
+	}
+}
+
+func TestNewOffline_AveragesRepeatedPostalCodes(t *testing.T) {
+	// arrange
+	table := "DE\t10115\tBerlin\t\t\t\t\t\t\t52.0\t13.0\t4\n" +
+		"de\t10 115\tBerlin Mitte\t\t\t\t\t\t\t53.0\t14.0\t4\n"
+
+	// act
+	geocoder, err := NewOffline(strings.NewReader(table))
+
+	// assert
+	if err != nil {
+		t.Fatal(err)
+	}
+	if geocoder.Len() != 1 {
+		t.Errorf("Len, want: 1 got: %d", geocoder.Len())
+	}
+	got, _ := geocoder.Geocode(context.Background(), postal.Address{PostalCode: "10115", Country: "DE"})
+	if want := (Location{Lat: 52.5, Lng: 13.5}); got != want {
+		t.Errorf("Geocode, want: %+v got: %+v", want, got)
+	}
+}
+
+func TestNewOffline_RejectsMalformedLines(t *testing.T) {
+	tests := []string{
+		"US\t62701\tSpringfield\n",
+		"US\t62701\tSpringfield\t\t\t\t\t\t\tnorth\t-89.6\t4\n",
+		"US\t62701\tSpringfield\t\t\t\t\t\t\t39.8\t-189.6\t4\n",
+	}
+
+	for _, table := range tests {
+		// act
+		_, err := NewOffline(strings.NewReader(table))
+
+		// assert
+		if err == nil {
+			t.Errorf("NewOffline(%q) error, want: error got: nil", table)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Checker reports whether a dependency is healthy by returning a nil error.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts an ordinary function to the Checker interface.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Registry holds a named set of checks that are run together.
+type Registry struct {
+	mu      sync.RWMutex
+	timeout time.Duration
+	checks  []namedChecker
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Report is the outcome of running every check in a Registry.
+type Report struct {
+	Healthy bool
+	Checks  []Result
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// NewRegistry creates an empty registry. Each check is cancelled if it runs longer than timeout;
+// a timeout of zero means checks are only bound by the caller's context.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout}
+}
+
+// Register adds a check; checks are reported in the order they were registered.
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, namedChecker{name: name, checker: checker})
+}
+
+// RegisterFunc adds a check implemented by a plain function.
+func (r *Registry) RegisterFunc(name string, f func(ctx context.Context) error) {
+	r.Register(name, CheckerFunc(f))
+}
+
+// Run executes all checks concurrently and waits for them to finish. An empty registry is healthy.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]namedChecker, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check namedChecker) {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Healthy: true, Checks: results}
+	for _, result := range results {
+		if result.Err != nil {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, check namedChecker) Result {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check.checker.Check(ctx)
+	return Result{
+		Name:     check.name,
+		Err:      err,
+		Duration: time.Since(start),
+	}
+}
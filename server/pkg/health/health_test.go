@@ -0,0 +1,70 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistry_RunEmptyIsHealthy(t *testing.T) {
+	// arrange
+	registry := NewRegistry(time.Second)
+
+	// act
+	report := registry.Run(context.Background())
+
+	// assert
+	if !report.Healthy {
+		t.Errorf("Healthy, want: true got: false")
+	}
+	if len(report.Checks) != 0 {
+		t.Errorf("len(Checks), want: 0 got: %d", len(report.Checks))
+	}
+}
+
+func TestRegistry_RunReportsEachCheckInOrder(t *testing.T) {
+	// arrange
+	registry := NewRegistry(time.Second)
+	failure := errors.New("connection refused")
+
+	registry.RegisterFunc("database", func(ctx context.Context) error { return failure })
+	registry.RegisterFunc("migrations", func(ctx context.Context) error { return nil })
+
+	// act
+	report := registry.Run(context.Background())
+
+	// assert
+	if report.Healthy {
+		t.Errorf("Healthy, want: false got: true")
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("len(Checks), want: 2 got: %d", len(report.Checks))
+	}
+	if report.Checks[0].Name != "database" || report.Checks[0].Err != failure {
+		t.Errorf("Checks[0], want: database/%v got: %s/%v", failure, report.Checks[0].Name, report.Checks[0].Err)
+	}
+	if report.Checks[1].Name != "migrations" || report.Checks[1].Err != nil {
+		t.Errorf("Checks[1], want: migrations/<nil> got: %s/%v", report.Checks[1].Name, report.Checks[1].Err)
+	}
+}
+
+func TestRegistry_RunCancelsSlowChecks(t *testing.T) {
+	// arrange
+	registry := NewRegistry(10 * time.Millisecond)
+	registry.RegisterFunc("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// act
+	report := registry.Run(context.Background())
+
+	// assert
+	if report.Healthy {
+		t.Errorf("Healthy, want: false got: true")
+	}
+	if report.Checks[0].Err != context.DeadlineExceeded {
+		t.Errorf("Err, want: %v got: %v", context.DeadlineExceeded, report.Checks[0].Err)
+	}
+}
@@ -1,26 +1,150 @@
 package log
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/jsternberg/zap-logfmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var logger *zap.Logger
+// encodings supported by Configure
+const (
+	EncodingLogfmt  = "logfmt"
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+// Config controls the logger set up by Configure.
+type Config struct {
+	Level    string // debug, info, warn or error
+	Encoding string // logfmt, json or console
+
+	// File is the path to write to, stdout when empty. The file is rotated once it reaches
+	// MaxSizeMB, keeping at most MaxBackups old files for at most MaxAgeDays.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+
+	// SampleInitial and SampleThereafter limit repeated debug and info lines: each second, the
+	// first SampleInitial entries with a given message are logged, then every SampleThereafter-th.
+	// warnings and errors are never sampled. zero disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+}
 
+var (
+	logger *zap.Logger
+	level  = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+)
+
+// until Configure is called, log everything to stdout as logfmt
 func init() {
+	logger = zap.New(zapcore.NewCore(newEncoder(EncodingLogfmt), os.Stdout, level))
+}
+
+// Configure replaces the logger. It isn't safe to call concurrently with logging, so call it
+// once at startup before any goroutines are started.
+func Configure(config Config) error {
+	var minLevel zapcore.Level
+	if err := minLevel.UnmarshalText([]byte(config.Level)); err != nil {
+		return err
+	}
+
+	switch config.Encoding {
+	case EncodingLogfmt, EncodingJSON, EncodingConsole:
+	default:
+		return fmt.Errorf("unknown log encoding %q", config.Encoding)
+	}
+
+	level.SetLevel(minLevel)
+	encoder := newEncoder(config.Encoding)
+
+	var out io.Writer = os.Stdout
+	if config.File != "" {
+		out = &lumberjack.Logger{
+			Filename:   config.File,
+			MaxSize:    config.MaxSizeMB,
+			MaxBackups: config.MaxBackups,
+			MaxAge:     config.MaxAgeDays,
+		}
+	}
+	sink := zapcore.Lock(zapcore.AddSync(out))
+
+	if config.SampleInitial <= 0 || config.SampleThereafter <= 0 {
+		logger = zap.New(zapcore.NewCore(encoder, sink, level))
+		return nil
+	}
+
+	// split the core by level so only debug and info lines are sampled
+	low := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return level.Enabled(l) && l <= zapcore.InfoLevel
+	})
+	high := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		return level.Enabled(l) && l > zapcore.InfoLevel
+	})
+
+	logger = zap.New(zapcore.NewTee(
+		zapcore.NewSamplerWithOptions(zapcore.NewCore(encoder, sink, low), time.Second, config.SampleInitial, config.SampleThereafter),
+		zapcore.NewCore(encoder, sink, high),
+	))
+	return nil
+}
+
+func newEncoder(encoding string) zapcore.Encoder {
 	config := zap.NewProductionEncoderConfig()
 	config.EncodeTime = func(ts time.Time, encoder zapcore.PrimitiveArrayEncoder) {
 		encoder.AppendString(ts.UTC().Format(time.RFC3339))
 	}
-	logger = zap.New(zapcore.NewCore(
-		zaplogfmt.NewEncoder(config),
-		os.Stdout,
-		zapcore.DebugLevel,
-	))
+
+	switch encoding {
+	case EncodingJSON:
+		return zapcore.NewJSONEncoder(config)
+	case EncodingConsole:
+		return zapcore.NewConsoleEncoder(config)
+	default:
+		return zaplogfmt.NewEncoder(config)
+	}
+}
+
+// LevelHandler reports the current level on GET and changes it on PUT, e.g.
+//
+//	curl -X PUT -H "Authorization: Bearer $TOKEN" -d '{"level":"debug"}' http://127.0.0.1:8423/log/level
+func LevelHandler() http.Handler {
+	return level
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying fields, in addition to any it already carries, that
+// are added to every line logged through FromContext.
+func NewContext(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := contextFields(ctx)
+	combined := make([]zap.Field, 0, len(existing)+len(fields))
+	combined = append(combined, existing...)
+	combined = append(combined, fields...)
+	return context.WithValue(ctx, contextKey{}, combined)
+}
+
+// FromContext returns the logger with the request-scoped fields carried by ctx.
+func FromContext(ctx context.Context) *zap.Logger {
+	return logger.With(contextFields(ctx)...)
+}
+
+func contextFields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(contextKey{}).([]zap.Field)
+	return fields
+}
+
+func Debug(msg string, fields ...zap.Field) {
+	logger.Debug(msg, fields...)
 }
 
 func Info(msg string, fields ...zap.Field) {
@@ -35,6 +159,12 @@ func Error(msg string, fields ...zap.Field) {
 	logger.Error(msg, fields...)
 }
 
-func Fatal(err error) {
-	logger.Fatal(err.Error())
+// Fatal logs the message then exits the process with status 1.
+func Fatal(msg string, fields ...zap.Field) {
+	logger.Fatal(msg, fields...)
+}
+
+// Sync flushes buffered log entries; call it before the process exits.
+func Sync() error {
+	return logger.Sync()
 }
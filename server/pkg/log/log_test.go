@@ -0,0 +1,51 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNewContext_AppendsFields(t *testing.T) {
+	// arrange
+	ctx := NewContext(context.Background(), zap.String("request_id", "abc"))
+
+	// act
+	ctx = NewContext(ctx, zap.String("trace_id", "def"))
+
+	// assert
+	fields := contextFields(ctx)
+	if len(fields) != 2 {
+		t.Fatalf("len(fields), want: 2 got: %d", len(fields))
+	}
+	if fields[0].Key != "request_id" || fields[1].Key != "trace_id" {
+		t.Errorf("fields, want: [request_id trace_id] got: [%s %s]", fields[0].Key, fields[1].Key)
+	}
+}
+
+func TestNewContext_DoesNotModifyParent(t *testing.T) {
+	// arrange
+	parent := NewContext(context.Background(), zap.String("request_id", "abc"))
+
+	// act
+	NewContext(parent, zap.String("trace_id", "def"))
+
+	// assert
+	if fields := contextFields(parent); len(fields) != 1 {
+		t.Errorf("len(fields), want: 1 got: %d", len(fields))
+	}
+}
+
+func TestConfigure_RejectsUnknownSettings(t *testing.T) {
+	tests := []Config{
+		{Level: "verbose", Encoding: EncodingLogfmt},
+		{Level: "info", Encoding: "xml"},
+	}
+
+	for _, config := range tests {
+		if err := Configure(config); err == nil {
+			t.Errorf("Configure(%+v), want: error got: <nil>", config)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package postal validates, normalizes and formats postal addresses according to the conventions
+// of their country.
+package postal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address is a postal address. Country is an ISO 3166-1 alpha-2 code.
+type Address struct {
+	Line1         string
+	Line2         string
+	City          string
+	StateProvince string
+	PostalCode    string
+	Country       string
+}
+
+// rules are the conventions of one country. Countries without rules only have their fields trimmed.
+type rules struct {
+	subdivisions        map[string]string // code to name; if set StateProvince must be one of them
+	subdivisionName     string            // e.g. state, used in errors
+	postalCode          *regexp.Regexp    // matched against the postal code after normalizePostal
+	postalCodeExample   string
+	normalizePostalCode func(string) string
+	format              func(Address) []string // the lines after line1 and line2
+}
+
+var countryRules = map[string]rules{
+	"US": {
+		subdivisions:        usStates,
+		subdivisionName:     "state",
+		postalCode:          regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`),
+		postalCodeExample:   "12345 or 12345-6789",
+		normalizePostalCode: normalizeZIP,
+		format:              cityStatePostal(", ", " "),
+	},
+	"CA": {
+		subdivisions:        caProvinces,
+		subdivisionName:     "province or territory",
+		postalCode:          regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z] [0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+		postalCodeExample:   "K1A 0B1",
+		normalizePostalCode: spaceBeforeLast(3),
+		format:              cityStatePostal(" ", "  "),
+	},
+	"AU": {
+		subdivisions:        auStates,
+		subdivisionName:     "state or territory",
+		postalCode:          regexp.MustCompile(`^[0-9]{4}$`),
+		postalCodeExample:   "2000",
+		normalizePostalCode: removeSpaces,
+		format:              cityStatePostal(" ", "  "),
+	},
+	"GB": {
+		postalCode:          regexp.MustCompile(`^[A-Z]{1,2}[0-9][A-Z0-9]? [0-9][A-Z]{2}$`),
+		postalCodeExample:   "SW1A 1AA",
+		normalizePostalCode: spaceBeforeLast(3),
+		format: func(a Address) []string {
+			return []string{strings.ToUpper(a.City), a.StateProvince, a.PostalCode}
+		},
+	},
+	"DE": {
+		postalCode:          regexp.MustCompile(`^[0-9]{5}$`),
+		postalCodeExample:   "10117",
+		normalizePostalCode: removeSpaces,
+		format:              postalCity,
+	},
+	"FR": {
+		postalCode:          regexp.MustCompile(`^[0-9]{5}$`),
+		postalCodeExample:   "75008",
+		normalizePostalCode: removeSpaces,
+		format: func(a Address) []string {
+			return []string{a.PostalCode + " " + strings.ToUpper(a.City)}
+		},
+	},
+	"NL": {
+		postalCode:          regexp.MustCompile(`^[1-9][0-9]{3} [A-Z]{2}$`),
+		postalCodeExample:   "1012 JS",
+		normalizePostalCode: spaceBeforeLast(2),
+		format: func(a Address) []string {
+			return []string{a.PostalCode + "  " + strings.ToUpper(a.City)}
+		},
+	},
+}
+
+// Normalize checks a against the conventions of its country and returns it in canonical form:
+// the country code and postal code upper case, the postal code punctuated as the country's post
+// does it, and the state or province as its postal code where the country has a list of them.
+func Normalize(a Address) (Address, error) {
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.StateProvince = strings.TrimSpace(a.StateProvince)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+
+	code, ok := CountryCode(a.Country)
+	if !ok {
+		return Address{}, fmt.Errorf("country %q must be an ISO 3166-1 alpha-2 code such as US", a.Country)
+	}
+	a.Country = code
+
+	r, ok := countryRules[a.Country]
+	if !ok {
+		return a, nil
+	}
+
+	if r.subdivisions != nil {
+		subdivision, ok := lookup(r.subdivisions, a.StateProvince)
+		if !ok {
+			return Address{}, fmt.Errorf("stateProvince %q must be a %s %s code or name", a.StateProvince, a.Country, r.subdivisionName)
+		}
+		a.StateProvince = subdivision
+	}
+
+	if r.postalCode != nil {
+		postalCode := strings.ToUpper(a.PostalCode)
+		if r.normalizePostalCode != nil {
+			postalCode = r.normalizePostalCode(postalCode)
+		}
+		if !r.postalCode.MatchString(postalCode) {
+			return Address{}, fmt.Errorf("postalCode %q must be a %s postal code such as %s", a.PostalCode, a.Country, r.postalCodeExample)
+		}
+		a.PostalCode = postalCode
+	}
+
+	return a, nil
+}
+
+// Format returns a as the lines of a mailing label, in its country's order, ending with the
+// country's name. Empty lines are left out.
+func Format(a Address) string {
+	lines := []string{a.Line1, a.Line2}
+	if r, ok := countryRules[a.Country]; ok && r.format != nil {
+		lines = append(lines, r.format(a)...)
+	} else {
+		lines = append(lines, strings.Join(nonEmpty(a.City, a.StateProvince, a.PostalCode), " "))
+	}
+	if name, ok := countries[a.Country]; ok {
+		lines = append(lines, strings.ToUpper(name))
+	}
+	return strings.Join(nonEmpty(lines...), "\n")
+}
+
+// CountryCode returns the ISO 3166-1 alpha-2 code for a code or English short name, ignoring case.
+func CountryCode(nameOrCode string) (string, bool) {
+	return lookup(countries, nameOrCode)
+}
+
+// CountryName returns the English short name of the country with the ISO 3166-1 alpha-2 code.
+func CountryName(code string) (string, bool) {
+	name, ok := countries[code]
+	return name, ok
+}
+
+// lookup returns the code in codes matching s as a code or name, ignoring case.
+func lookup(codes map[string]string, s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if _, ok := codes[strings.ToUpper(s)]; ok {
+		return strings.ToUpper(s), true
+	}
+	for code, name := range codes {
+		if strings.EqualFold(name, s) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// cityStatePostal formats the last line North American style, e.g. "Calera, AL 35040".
+func cityStatePostal(afterCity, afterState string) func(Address) []string {
+	return func(a Address) []string {
+		return []string{a.City + afterCity + a.StateProvince + afterState + a.PostalCode}
+	}
+}
+
+// postalCity formats the last line as most of continental Europe does, e.g. "10117 Berlin".
+func postalCity(a Address) []string {
+	return []string{a.PostalCode + " " + a.City}
+}
+
+// normalizeZIP formats a nine digit ZIP+4 code written without its hyphen.
+func normalizeZIP(s string) string {
+	s = removeSpaces(s)
+	if len(s) == 9 && !strings.Contains(s, "-") {
+		return s[:5] + "-" + s[5:]
+	}
+	return s
+}
+
+// spaceBeforeLast puts a single space before the last n characters, the inward code of a British
+// or Canadian postal code.
+func spaceBeforeLast(n int) func(string) string {
+	return func(s string) string {
+		s = removeSpaces(s)
+		if len(s) <= n {
+			return s
+		}
+		return s[:len(s)-n] + " " + s[len(s)-n:]
+	}
+}
+
+func removeSpaces(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+func nonEmpty(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package postal
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name      string
+		address   Address
+		want      Address
+		wantError string
+	}{
+		{
+			name:    "US state name and ZIP+4 without hyphen",
+			address: Address{City: " Calera ", StateProvince: "alabama", PostalCode: "350401234", Country: "us"},
+			want:    Address{City: "Calera", StateProvince: "AL", PostalCode: "35040-1234", Country: "US"},
+		},
+		{
+			name:    "country by name",
+			address: Address{StateProvince: "DC", PostalCode: "20006", Country: "United States"},
+			want:    Address{StateProvince: "DC", PostalCode: "20006", Country: "US"},
+		},
+		{
+			name:    "Canadian postal code without space",
+			address: Address{StateProvince: "Quebec", PostalCode: "h3z2y7", Country: "CA"},
+			want:    Address{StateProvince: "QC", PostalCode: "H3Z 2Y7", Country: "CA"},
+		},
+		{
+			name:    "Australian state",
+			address: Address{StateProvince: "new south wales", PostalCode: "2000", Country: "AU"},
+			want:    Address{StateProvince: "NSW", PostalCode: "2000", Country: "AU"},
+		},
+		{
+			name:    "British postcode spacing, county kept",
+			address: Address{StateProvince: "Greater London", PostalCode: "sw1a1aa", Country: "GB"},
+			want:    Address{StateProvince: "Greater London", PostalCode: "SW1A 1AA", Country: "GB"},
+		},
+		{
+			name:    "Dutch postcode",
+			address: Address{PostalCode: "1012js", Country: "NL"},
+			want:    Address{PostalCode: "1012 JS", Country: "NL"},
+		},
+		{
+			name:    "country without rules is only trimmed",
+			address: Address{StateProvince: " Tokyo ", PostalCode: " 100-0001 ", Country: "jp"},
+			want:    Address{StateProvince: "Tokyo", PostalCode: "100-0001", Country: "JP"},
+		},
+		{
+			name:      "unknown country",
+			address:   Address{Country: "XX"},
+			wantError: `country "XX" must be an ISO 3166-1 alpha-2 code such as US`,
+		},
+		{
+			name:      "unknown state",
+			address:   Address{StateProvince: "Albama", PostalCode: "35040", Country: "US"},
+			wantError: `stateProvince "Albama" must be a US state code or name`,
+		},
+		{
+			name:      "bad ZIP",
+			address:   Address{StateProvince: "AL", PostalCode: "3504", Country: "US"},
+			wantError: `postalCode "3504" must be a US postal code such as 12345 or 12345-6789`,
+		},
+		{
+			name:      "bad German postal code",
+			address:   Address{PostalCode: "1011", Country: "DE"},
+			wantError: `postalCode "1011" must be a DE postal code such as 10117`,
+		},
+	}
+
+	for _, tt := range tests {
+		// act
+		got, err := Normalize(tt.address)
+
+		// assert
+		if tt.wantError != "" {
+			if err == nil || err.Error() != tt.wantError {
+				t.Errorf("%s: error, want: %q got: %v", tt.name, tt.wantError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s, want: %+v got: %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		address Address
+		want    string
+	}{
+		{
+			Address{Line1: "679 Strother Street", Line2: "suite 3000", City: "Calera", StateProvince: "AL", PostalCode: "35040", Country: "US"},
+			"679 Strother Street\nsuite 3000\nCalera, AL 35040\nUNITED STATES",
+		},
+		{
+			Address{Line1: "10 Downing Street", City: "London", PostalCode: "SW1A 2AA", Country: "GB"},
+			"10 Downing Street\nLONDON\nSW1A 2AA\nUNITED KINGDOM",
+		},
+		{
+			Address{Line1: "Pariser Platz 1", City: "Berlin", PostalCode: "10117", Country: "DE"},
+			"Pariser Platz 1\n10117 Berlin\nGERMANY",
+		},
+		{
+			Address{Line1: "1-1 Chiyoda", City: "Chiyoda-ku", StateProvince: "Tokyo", PostalCode: "100-0001", Country: "JP"},
+			"1-1 Chiyoda\nChiyoda-ku Tokyo 100-0001\nJAPAN",
+		},
+	}
+
+	for _, tt := range tests {
+		// act
+		got := Format(tt.address)
+
+		// assert
+		if got != tt.want {
+			t.Errorf("want:\n%s\ngot:\n%s", tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	s := NewMemoryStore()
+	s.now = func() time.Time { return *now }
+	return s
+}
+
+func TestMemoryStore_AllowsBurstThenDenies(t *testing.T) {
+	// arrange
+	now := time.Now()
+	store := newTestStore(&now)
+	limit := Limit{Rate: 1, Burst: 3}
+
+	// act
+	var results []Result
+	for i := 0; i < 4; i++ {
+		result, err := store.Take(context.Background(), "ip:10.0.0.1", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	// assert
+	for i := 0; i < 3; i++ {
+		if !results[i].Allowed {
+			t.Errorf("results[%d].Allowed, want: true got: false", i)
+		}
+		if want := 2 - i; results[i].Remaining != want {
+			t.Errorf("results[%d].Remaining, want: %d got: %d", i, want, results[i].Remaining)
+		}
+	}
+	if results[3].Allowed {
+		t.Errorf("results[3].Allowed, want: false got: true")
+	}
+	if results[3].RetryAfter != time.Second {
+		t.Errorf("results[3].RetryAfter, want: %v got: %v", time.Second, results[3].RetryAfter)
+	}
+	if results[3].Reset != 3*time.Second {
+		t.Errorf("results[3].Reset, want: %v got: %v", 3*time.Second, results[3].Reset)
+	}
+}
+
+func TestMemoryStore_RefillsOverTime(t *testing.T) {
+	// arrange
+	now := time.Now()
+	store := newTestStore(&now)
+	limit := Limit{Rate: 2, Burst: 1}
+
+	if result, _ := store.Take(context.Background(), "k", limit); !result.Allowed {
+		t.Fatalf("first request, want: allowed got: denied")
+	}
+
+	// act
+	now = now.Add(500 * time.Millisecond)
+	result, err := store.Take(context.Background(), "k", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assert
+	if !result.Allowed {
+		t.Errorf("Allowed after refill, want: true got: false")
+	}
+}
+
+func TestMemoryStore_KeysAreIndependent(t *testing.T) {
+	// arrange
+	now := time.Now()
+	store := newTestStore(&now)
+	limit := Limit{Rate: 1, Burst: 1}
+
+	// act
+	a, _ := store.Take(context.Background(), "ip:10.0.0.1", limit)
+	b, _ := store.Take(context.Background(), "ip:10.0.0.2", limit)
+
+	// assert
+	if !a.Allowed || !b.Allowed {
+		t.Errorf("Allowed, want: true, true got: %v, %v", a.Allowed, b.Allowed)
+	}
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	// arrange
+	now := time.Now()
+	store := newTestStore(&now)
+	limit := Limit{Rate: 1, Burst: 1}
+	store.Take(context.Background(), "old", limit)
+
+	// act
+	now = now.Add(2 * sweepInterval)
+	store.Take(context.Background(), "new", limit)
+
+	// assert
+	if _, ok := store.buckets["old"]; ok {
+		t.Errorf("bucket %q, want: swept got: still present", "old")
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "60/1m", want: Limit{Rate: 1, Burst: 60}},
+		{in: "10/1s", want: Limit{Rate: 10, Burst: 10}},
+		{in: "off", want: Limit{}},
+		{in: "", want: Limit{}},
+		{in: "60", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "60/soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		// act
+		got, err := ParseLimit(tt.in)
+
+		// assert
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q) error, want: %v got: %v", tt.in, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q), want: %+v got: %+v", tt.in, tt.want, got)
+		}
+	}
+}
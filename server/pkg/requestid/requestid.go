@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the HTTP header a request ID is read from and echoed in.
+const Header = "X-Request-ID"
+
+// maxLength bounds inbound IDs so a client can't stuff arbitrary data into our logs.
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a random 128-bit request ID, hex encoded.
+func New() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails if the OS can't provide randomness, which we can't recover from
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// IsValid reports whether an inbound ID is safe to reuse: non-empty, at most 128 characters and
+// limited to letters, digits and - _ . : characters.
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request ID.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" if there isn't one.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
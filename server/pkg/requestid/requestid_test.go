@@ -0,0 +1,61 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	// act
+	a, b := New(), New()
+
+	// assert
+	if len(a) != 32 {
+		t.Errorf("len(New()), want: 32 got: %d", len(a))
+	}
+	if a == b {
+		t.Errorf("New() returned %q twice", a)
+	}
+	if !IsValid(a) {
+		t.Errorf("IsValid(%q), want: true got: false", a)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"", false},
+		{"4bf92f3580b34a1c9d5a0e3e2f1d7c6b", true},
+		{"req-123_abc.def:9", true},
+		{"has space", false},
+		{"line\nbreak", false},
+		{"<script>", false},
+		{strings.Repeat("a", 128), true},
+		{strings.Repeat("a", 129), false},
+	}
+
+	for _, test := range tests {
+		if got := IsValid(test.id); got != test.want {
+			t.Errorf("IsValid(%q), want: %v got: %v", test.id, test.want, got)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	// arrange
+	ctx := NewContext(context.Background(), "abc")
+
+	// act
+	got := FromContext(ctx)
+
+	// assert
+	if got != "abc" {
+		t.Errorf("FromContext, want: %q got: %q", "abc", got)
+	}
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext(Background), want: \"\" got: %q", got)
+	}
+}